@@ -0,0 +1,61 @@
+package gobuf
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Allocation", func() {
+	It("should write numbers without allocation", func() {
+		b := New(make([]byte, 64))
+		allocs := testing.AllocsPerRun(100, func() {
+			b.ResetWriter()
+			_ = b.WriteBool(true)
+			_ = b.WriteByte(1)
+			_ = b.WriteUint8(8)
+			_ = b.WriteUint16(16)
+			_ = b.WriteUint32(32)
+			_ = b.WriteUint64(64)
+			_ = b.WriteInt8(-8)
+			_ = b.WriteInt16(-16)
+			_ = b.WriteInt32(-32)
+			_ = b.WriteInt64(-64)
+			_ = b.WriteFloat32(32.32)
+			_ = b.WriteFloat64(64.64)
+			_ = b.WriteUvarint(1 << 40)
+			_ = b.WriteVarint(-1 << 40)
+			_ = b.WriteSLEB128(-1 << 40)
+		})
+		Expect(allocs).To(BeZero())
+	})
+
+	It("should peek and read numbers without allocation", func() {
+		b := New(make([]byte, 64))
+		allocs := testing.AllocsPerRun(100, func() {
+			b.ResetReader()
+			_, _ = b.PeekBool()
+			_, _ = b.PeekUint16(1)
+			_, _ = b.PeekUint32(2)
+			_, _ = b.PeekInt64(3)
+			_, _ = b.PeekFloat64(4)
+			_, _, _ = b.PeekUvarint(5)
+			_, _ = b.ReadBool()
+			_, _ = b.ReadByte()
+			_, _ = b.ReadUint8()
+			_, _ = b.ReadUint16()
+			_, _ = b.ReadUint32()
+			_, _ = b.ReadUint64()
+			_, _ = b.ReadInt8()
+			_, _ = b.ReadInt16()
+			_, _ = b.ReadInt32()
+			_, _ = b.ReadInt64()
+			_, _ = b.ReadFloat32()
+			_, _ = b.ReadFloat64()
+			_, _ = b.ReadVarint()
+			_, _ = b.ReadSLEB128()
+		})
+		Expect(allocs).To(BeZero())
+	})
+})
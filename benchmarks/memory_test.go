@@ -3,6 +3,7 @@ package benchmarks
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
 	"strings"
 	"testing"
 
@@ -21,6 +22,9 @@ var memoryTestTable = []struct {
 	func() gobuf.Memory { return gobuf.NewSliceMemory(nil, gobuf.FixedGrow(1024*1024)) }, "Slice Grow:1M",
 }, {
 	func() gobuf.Memory { return gobuf.NewListMemory(nil, gobuf.FixedGrow(1024*1024)) }, "List Grow:1M",
+}, {
+	// many small nodes, offset lookup dominates
+	func() gobuf.Memory { return gobuf.NewListMemory(nil, gobuf.FixedGrow(1024)) }, "List Grow:1K",
 }}
 
 var memoryTestSizes = []int{16, 256, 1024, 1024 * 4}
@@ -107,3 +111,64 @@ func BenchmarkMemory(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkListMemoryRandomRead read at random locations of a list with many nodes
+func BenchmarkListMemoryRandomRead(b *testing.B) {
+	for _, nodes := range []int{16, 256, 4096} {
+		b.Run(fmt.Sprintf("Nodes:%d", nodes), func(b *testing.B) {
+			const nodeSize = 1024
+			mem := gobuf.NewListMemory(nil, gobuf.FixedGrow(nodeSize))
+			for i := 0; i < nodes; i++ {
+				if err := mem.Write(i*nodeSize, make([]byte, nodeSize)); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			rnd := rand.New(rand.NewSource(1))
+			dst := make([]byte, 64)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := mem.Read(rnd.Intn(nodes*nodeSize-len(dst)), dst); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkListMemoryLength length of a list with many nodes
+func BenchmarkListMemoryLength(b *testing.B) {
+	mem := gobuf.NewListMemory(nil, gobuf.FixedGrow(1024))
+	for i := 0; i < 4096; i++ {
+		if err := mem.Write(i*1024, make([]byte, 1024)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if mem.Length() != 4096*1024 {
+			b.Fatal("unexpected length")
+		}
+	}
+}
+
+func BenchmarkSliceMemoryAutoDiscard(b *testing.B) {
+	for _, size := range []int{4 * 1024, 1024 * 1024} {
+		sizeName := strings.ReplaceAll(humanize.IBytes(uint64(size)), " ", "")
+		b.Run(sizeName, func(b *testing.B) {
+			buf := gobuf.New(nil, gobuf.WithAutoGrowMemory(gobuf.FixedGrow(size)), gobuf.WithAutoDiscard(64))
+			body := make([]byte, 64)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := buf.WriteBytes(body); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := buf.ReadBytes(len(body)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package benchmarks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/joesonw/gobuf"
+)
+
+const numericBatch = 1024
+
+func BenchmarkNumeric(b *testing.B) {
+	b.Run("Write", func(b *testing.B) {
+		b.Run("GoBuf", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := gobuf.New(make([]byte, numericBatch*4))
+			for i := 0; i < b.N; i++ {
+				if i%numericBatch == 0 {
+					buf.ResetWriter()
+				}
+				if err := buf.WriteUint32(uint32(i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("EncodingBinary", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := bytes.NewBuffer(make([]byte, 0, numericBatch*4))
+			for i := 0; i < b.N; i++ {
+				if i%numericBatch == 0 {
+					buf.Reset()
+				}
+				if err := binary.Write(buf, binary.LittleEndian, uint32(i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("GoBuffer", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := bytes.NewBuffer(make([]byte, 0, numericBatch*4))
+			var scratch [4]byte
+			for i := 0; i < b.N; i++ {
+				if i%numericBatch == 0 {
+					buf.Reset()
+				}
+				binary.LittleEndian.PutUint32(scratch[:], uint32(i))
+				buf.Write(scratch[:])
+			}
+		})
+	})
+
+	b.Run("Read", func(b *testing.B) {
+		b.Run("GoBuf", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := gobuf.New(make([]byte, numericBatch*4))
+			for i := 0; i < b.N; i++ {
+				if i%numericBatch == 0 {
+					buf.ResetReader()
+				}
+				if _, err := buf.ReadUint32(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("EncodingBinary", func(b *testing.B) {
+			b.ReportAllocs()
+			data := make([]byte, numericBatch*4)
+			reader := bytes.NewReader(data)
+			var v uint32
+			for i := 0; i < b.N; i++ {
+				if i%numericBatch == 0 {
+					reader.Reset(data)
+				}
+				if err := binary.Read(reader, binary.LittleEndian, &v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("GoBuffer", func(b *testing.B) {
+			b.ReportAllocs()
+			data := make([]byte, numericBatch*4)
+			buf := bytes.NewBuffer(data)
+			var scratch [4]byte
+			for i := 0; i < b.N; i++ {
+				if i%numericBatch == 0 {
+					buf = bytes.NewBuffer(data)
+				}
+				if _, err := buf.Read(scratch[:]); err != nil {
+					b.Fatal(err)
+				}
+				_ = binary.LittleEndian.Uint32(scratch[:])
+			}
+		})
+	})
+}
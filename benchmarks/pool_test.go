@@ -0,0 +1,85 @@
+package benchmarks
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/joesonw/gobuf"
+)
+
+const poolRequestSize = 16 * 1024
+
+// reportGC report number of garbage collections per op since before
+func reportGC(b *testing.B, before *runtime.MemStats) {
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gc/op")
+}
+
+func BenchmarkPool(b *testing.B) {
+	payload := make([]byte, 512)
+
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for i := 0; i < b.N; i++ {
+			buf := gobuf.New(nil, gobuf.WithAutoGrowMemory(gobuf.FixedGrow(1024)))
+			for buf.Size() < poolRequestSize {
+				if err := buf.WriteBytes(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		reportGC(b, &before)
+	})
+
+	b.Run("BufferPool", func(b *testing.B) {
+		b.ReportAllocs()
+		pool := gobuf.NewBufferPool()
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for i := 0; i < b.N; i++ {
+			buf := pool.Get(1024)
+			for buf.Size() < poolRequestSize {
+				if err := buf.WriteBytes(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+			buf.Release()
+		}
+		reportGC(b, &before)
+	})
+
+	b.Run("ListMemory", func(b *testing.B) {
+		b.ReportAllocs()
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for i := 0; i < b.N; i++ {
+			buf := gobuf.New(nil, gobuf.WithLinkedListMemory(gobuf.FixedGrow(1024)))
+			for buf.Size() < poolRequestSize {
+				if err := buf.WriteBytes(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		reportGC(b, &before)
+	})
+
+	b.Run("PooledListMemory", func(b *testing.B) {
+		b.ReportAllocs()
+		pool := gobuf.NewBufferPool()
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for i := 0; i < b.N; i++ {
+			buf := gobuf.New(nil, gobuf.WithMemory(pool.NewListMemory(gobuf.FixedGrow(1024))))
+			for buf.Size() < poolRequestSize {
+				if err := buf.WriteBytes(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+			buf.Release()
+		}
+		reportGC(b, &before)
+	})
+}
@@ -0,0 +1,88 @@
+package benchmarks
+
+import (
+	"encoding/binary"
+	"runtime"
+	"testing"
+
+	"github.com/joesonw/gobuf"
+)
+
+const spscRecordSize = 64
+
+func BenchmarkSPSC(b *testing.B) {
+	record := make([]byte, spscRecordSize)
+
+	b.Run("Chan", func(b *testing.B) {
+		b.SetBytes(spscRecordSize)
+		ch := make(chan []byte, 1024)
+		go func() {
+			for i := 0; i < b.N; i++ {
+				out := make([]byte, len(record))
+				copy(out, record)
+				ch <- out
+			}
+			close(ch)
+		}()
+		for range ch {
+		}
+	})
+
+	b.Run("SPSCQueue", func(b *testing.B) {
+		b.SetBytes(spscRecordSize)
+		q := gobuf.NewSPSCQueue(1024*(spscRecordSize+4), binary.LittleEndian)
+		go func() {
+			for i := 0; i < b.N; {
+				if err := q.Enqueue(record); err == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+		dst := make([]byte, spscRecordSize)
+		for i := 0; i < b.N; {
+			if err := q.DequeueWith(func(r *gobuf.Reader) error {
+				_, err := r.Read(dst)
+				return err
+			}); err == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	})
+
+	b.Run("SPSCQueueBatch", func(b *testing.B) {
+		b.SetBytes(spscRecordSize)
+		q := gobuf.NewSPSCQueue(1024*(spscRecordSize+4), binary.LittleEndian)
+		go func() {
+			batch := make([][]byte, 16)
+			for i := range batch {
+				batch[i] = record
+			}
+			for i := 0; i < b.N; {
+				remain := batch
+				if b.N-i < len(remain) {
+					remain = remain[:b.N-i]
+				}
+				n, _ := q.EnqueueBatch(remain)
+				if n == 0 {
+					runtime.Gosched()
+				}
+				i += n
+			}
+		}()
+		dst := make([]byte, spscRecordSize)
+		for i := 0; i < b.N; {
+			n, _ := q.DequeueBatch(16, func(r *gobuf.Reader) error {
+				_, err := r.Read(dst)
+				return err
+			})
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package gobuf
+
+// BitOrder order bits are packed within a byte
+type BitOrder int
+
+const (
+	// MSBFirst first bit goes into the most significant bit of a byte
+	MSBFirst BitOrder = iota + 1
+	// LSBFirst first bit goes into the least significant bit of a byte
+	LSBFirst
+)
+
+// maxBits max number of bits read or written at once
+const maxBits = 64
+
+func bitMask(n int) uint64 {
+	if n >= maxBits {
+		return ^uint64(0)
+	}
+	return 1<<uint(n) - 1
+}
+
+// BitWriter write bit fields through a Writer.
+// a partially filled byte is held until it is complete or AlignToByte is called,
+// so WriterIndex only covers complete bytes. align before writing bytes through the Writer directly
+type BitWriter struct {
+	*Writer
+	order BitOrder
+	// pending bits of current byte
+	cur   byte
+	nbits int
+}
+
+func NewBitWriter(w *Writer, order BitOrder) *BitWriter {
+	return &BitWriter{
+		Writer: w,
+		order:  order,
+	}
+}
+
+// BitIndex number of bits written, including pending bits
+func (w *BitWriter) BitIndex() int {
+	return w.WriterIndex()*8 + w.nbits
+}
+
+// WriteBits write lower n bits of v, n must be within [0, 64]
+func (w *BitWriter) WriteBits(v uint64, n int) error {
+	if n < 0 || n > maxBits {
+		return ErrInvalidBitCount
+	}
+
+	v &= bitMask(n)
+	for n > 0 {
+		free := 8 - w.nbits
+		take := n
+		if take > free {
+			take = free
+		}
+
+		if w.order == LSBFirst {
+			w.cur |= byte(v&bitMask(take)) << uint(w.nbits)
+			v >>= uint(take)
+		} else {
+			w.cur |= byte((v>>uint(n-take))&bitMask(take)) << uint(free-take)
+		}
+		w.nbits += take
+		n -= take
+
+		if w.nbits == 8 {
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteBit write a single bit
+func (w *BitWriter) WriteBit(b bool) error {
+	if b {
+		return w.WriteBits(1, 1)
+	}
+	return w.WriteBits(0, 1)
+}
+
+// AlignToByte pad pending bits with zeros up to next byte boundary and write it
+func (w *BitWriter) AlignToByte() error {
+	if w.nbits == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *BitWriter) flush() error {
+	if err := w.WriteByte(w.cur); err != nil {
+		return err
+	}
+	w.cur = 0
+	w.nbits = 0
+	return nil
+}
+
+// BitReader read bit fields through a Reader.
+// bit offset is relative to ReaderIndex, which only advances past completely read bytes.
+// align before reading bytes through the Reader directly
+type BitReader struct {
+	*Reader
+	order BitOrder
+	// bits read of byte at ReaderIndex
+	bit int
+	// scratch space for up to 64 bits at any bit offset
+	scratch [maxBits/8 + 1]byte
+}
+
+func NewBitReader(r *Reader, order BitOrder) *BitReader {
+	return &BitReader{
+		Reader: r,
+		order:  order,
+	}
+}
+
+// BitIndex number of bits read
+func (r *BitReader) BitIndex() int {
+	return r.ReaderIndex()*8 + r.bit
+}
+
+// PeekBits peek next n bits without moving index, n must be within [0, 64]
+func (r *BitReader) PeekBits(n int) (uint64, error) {
+	if n < 0 || n > maxBits {
+		return 0, ErrInvalidBitCount
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	b := r.scratch[:(r.bit+n+7)/8]
+	if err := r.peekFull("PeekBits", b, nil); err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	got := 0
+	off := r.bit
+	for _, c := range b {
+		avail := 8 - off
+		take := n - got
+		if take > avail {
+			take = avail
+		}
+
+		if r.order == LSBFirst {
+			v |= (uint64(c>>uint(off)) & bitMask(take)) << uint(got)
+		} else {
+			v = v<<uint(take) | uint64(c>>uint(avail-take))&bitMask(take)
+		}
+		got += take
+		off = 0
+	}
+	return v, nil
+}
+
+// ReadBits read next n bits, n must be within [0, 64]
+func (r *BitReader) ReadBits(n int) (uint64, error) {
+	v, err := r.PeekBits(n)
+	if err != nil {
+		return 0, withOp(err, "ReadBits")
+	}
+
+	total := r.bit + n
+	r.SkipRead(total / 8)
+	r.bit = total % 8
+	return v, nil
+}
+
+// ReadBit read a single bit
+func (r *BitReader) ReadBit() (bool, error) {
+	v, err := r.ReadBits(1)
+	return v == 1, err
+}
+
+// AlignToByte skip rest of a partially read byte
+func (r *BitReader) AlignToByte() {
+	if r.bit == 0 {
+		return
+	}
+	r.SkipRead(1)
+	r.bit = 0
+}
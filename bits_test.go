@@ -0,0 +1,108 @@
+package gobuf
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bits", func() {
+	It("should pack MSB first", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		w := NewBitWriter(b.Writer, MSBFirst)
+		Expect(w.WriteBits(0x5, 3)).To(BeNil())
+		Expect(w.WriteBits(0x1f, 5)).To(BeNil())
+		Expect(w.WriteBits(0x4d2, 11)).To(BeNil())
+		Expect(w.BitIndex()).To(Equal(19))
+		Expect(b.WriterIndex()).To(Equal(2))
+		Expect(w.AlignToByte()).To(BeNil())
+		Expect(b.WriterIndex()).To(Equal(3))
+		Expect(w.WriteUint8(0xaa)).To(BeNil())
+		Expect(b.Bytes()[:4]).To(Equal([]byte{0xbf, 0x9a, 0x40, 0xaa}))
+
+		r := NewBitReader(b.Reader, MSBFirst)
+		v, err := r.PeekBits(3)
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint64(0x5)))
+		Expect(r.BitIndex()).To(Equal(0))
+
+		for _, f := range []struct {
+			v uint64
+			n int
+		}{{0x5, 3}, {0x1f, 5}, {0x4d2, 11}} {
+			v, err = r.ReadBits(f.n)
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(f.v))
+		}
+		Expect(r.BitIndex()).To(Equal(19))
+		Expect(b.ReaderIndex()).To(Equal(2))
+		r.AlignToByte()
+		Expect(b.ReaderIndex()).To(Equal(3))
+		u, err := r.ReadUint8()
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint8(0xaa)))
+	})
+
+	It("should pack LSB first", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		w := NewBitWriter(b.Writer, LSBFirst)
+		Expect(w.WriteBits(0x5, 3)).To(BeNil())
+		Expect(w.WriteBits(0x1f, 5)).To(BeNil())
+		Expect(w.WriteBits(0x4d2, 11)).To(BeNil())
+		Expect(w.AlignToByte()).To(BeNil())
+		Expect(b.Bytes()[:3]).To(Equal([]byte{0xfd, 0xd2, 0x04}))
+
+		r := NewBitReader(b.Reader, LSBFirst)
+		for _, f := range []struct {
+			v uint64
+			n int
+		}{{0x5, 3}, {0x1f, 5}, {0x4d2, 11}} {
+			v, err := r.ReadBits(f.n)
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(f.v))
+		}
+	})
+
+	It("should handle 64 bits at any offset", func() {
+		for _, order := range []BitOrder{MSBFirst, LSBFirst} {
+			b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+			w := NewBitWriter(b.Writer, order)
+			Expect(w.WriteBit(true)).To(BeNil())
+			Expect(w.WriteBits(0x0123456789abcdef, 64)).To(BeNil())
+			Expect(w.WriteBits(0xff, 0)).To(BeNil())
+			Expect(w.WriteBits(0xff, 2)).To(BeNil())
+			Expect(w.AlignToByte()).To(BeNil())
+			Expect(b.Size()).To(Equal(9))
+
+			r := NewBitReader(b.Reader, order)
+			bit, err := r.ReadBit()
+			Expect(err).To(BeNil())
+			Expect(bit).To(BeTrue())
+			v, err := r.ReadBits(64)
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(uint64(0x0123456789abcdef)))
+			v, err = r.ReadBits(2)
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(uint64(0x3)))
+			Expect(r.BitIndex()).To(Equal(67))
+		}
+	})
+
+	It("should report errors", func() {
+		b := New([]byte{0xff})
+		w := NewBitWriter(b.Writer, MSBFirst)
+		Expect(w.WriteBits(0, 65)).To(Equal(ErrInvalidBitCount))
+
+		r := NewBitReader(b.Reader, MSBFirst)
+		_, err := r.ReadBits(-1)
+		Expect(err).To(Equal(ErrInvalidBitCount))
+		_, err = r.ReadBits(9)
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(r.BitIndex()).To(Equal(0))
+		_, err = r.ReadBits(8)
+		Expect(err).To(BeNil())
+		_, err = r.ReadBits(1)
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
@@ -13,6 +13,21 @@ type Buffer struct {
 	mem     Memory
 	options []OptionFunc
 	order   binary.ByteOrder
+
+	// total bytes discarded from the front
+	discarded int
+	// discard read bytes before writing once reader index reaches it, 0 to disable
+	autoDiscard int
+	// size saved by MarkWriter
+	markSize int
+	// pool buffer goes back to on Release, nil if not pooled
+	pool *BufferPool
+	// Release has been called, later calls do nothing
+	released bool
+	// writes fail with ErrReadOnly
+	readOnly bool
+	// windows of views sharing mem, bytes from the start of the first one on are not discarded
+	views []*windowMemory
 }
 
 func New(buf []byte, options ...OptionFunc) *Buffer {
@@ -21,10 +36,10 @@ func New(buf []byte, options ...OptionFunc) *Buffer {
 		options: options,
 		order:   binary.LittleEndian,
 	}
-	b.size = b.mem.Length()
 	for _, option := range options {
 		option(b, buf)
 	}
+	b.size = b.mem.Length()
 	b.Peeker = NewPeeker(b)
 	b.Reader = NewRead(b, b.Peeker)
 	b.Writer = NewWriter(b)
@@ -32,40 +47,66 @@ func New(buf []byte, options ...OptionFunc) *Buffer {
 }
 
 func (buf *Buffer) PeekAt(at int, dst []byte) (n int, err error) {
-	length := buf.mem.Length()
-	if n > length {
+	n = len(dst)
+	if n == 0 {
+		return 0, nil
+	}
+	if at < 0 {
+		return 0, ErrNegativeOffset
+	}
+
+	// only bytes written so far are readable
+	size := buf.Size()
+	if at >= size {
 		return 0, io.EOF
 	}
 
-	n = len(dst)
-	if at+n > length {
-		n = buf.mem.Length() - at
+	if at+n > size {
+		n = size - at
 	}
 
-	err = buf.mem.Read(at, dst[:n])
-	return
+	if err = buf.mem.Read(at, dst[:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
 func (buf *Buffer) WriteSome(src []byte) (n int, err error) {
-	at := buf.WriterIndex()
+	if buf.readOnly {
+		return 0, newError("Write", buf.WriterIndex(), len(src), 0, ErrReadOnly)
+	}
+	if buf.autoDiscard > 0 && buf.ReaderIndex() >= buf.autoDiscard {
+		if err = buf.DiscardReadBytes(); err != nil {
+			return
+		}
+	}
 
-	size := at + len(src)
-	err = buf.mem.Write(at, src)
-	if err != nil {
+	if err = buf.writeAt(buf.WriterIndex(), src); err != nil {
 		return
 	}
+	return len(src), nil
+}
 
-	if size > buf.size {
-		buf.size = size
+func (buf *Buffer) writeAt(at int, src []byte) error {
+	if err := buf.mem.Write(at, src); err != nil {
+		return err
 	}
 
-	return len(src), nil
+	if size := at + len(src); size > buf.size {
+		buf.size = size
+	}
+	return nil
 }
 
 func (buf *Buffer) Size() int {
 	return buf.size
 }
 
+// peekSize implements sizedPeekable
+func (buf *Buffer) peekSize() int {
+	return buf.size
+}
+
 func (buf *Buffer) Bytes() []byte {
 	return buf.mem.Bytes()
 }
@@ -80,3 +121,260 @@ func (buf *Buffer) Reset() {
 	buf.ResetReader()
 	buf.ResetWriter()
 }
+
+// ReserveAt implements Patchable, reserved bytes stay in memory so they can be filled at any time unless discarded
+func (buf *Buffer) ReserveAt(at, n int) (int, error) {
+	if buf.readOnly {
+		return 0, newError("Reserve", at, n, 0, ErrReadOnly)
+	}
+	return at + buf.discarded, nil
+}
+
+// Fill implements Patchable
+func (buf *Buffer) Fill(key int, src []byte) error {
+	at := key - buf.discarded
+	if buf.readOnly {
+		return newError("Fill", at, len(src), 0, ErrReadOnly)
+	}
+	if at < 0 {
+		return newError("Fill", at, len(src), 0, ErrDiscarded)
+	}
+	if err := buf.mem.Write(at, src); err != nil {
+		return newError("Fill", at, len(src), 0, err)
+	}
+	return nil
+}
+
+// DiscardReadBytes drop bytes before reader index, unread bytes are moved to the front and both indexes are adjusted.
+// bytes after reader mark, writer mark or start of a view which is not released are kept.
+// read-only buffers fail as memory would be changed
+func (buf *Buffer) DiscardReadBytes() error {
+	if buf.readOnly {
+		return newError("DiscardReadBytes", buf.ReaderIndex(), 0, 0, ErrReadOnly)
+	}
+
+	n := buf.ReaderIndex()
+	if n > buf.size {
+		n = buf.size
+	}
+	if buf.Peeker.marked && buf.Peeker.mark < n {
+		n = buf.Peeker.mark
+	}
+	if buf.Writer.marked && buf.Writer.mark < n {
+		n = buf.Writer.mark
+	}
+	for _, view := range buf.views {
+		if start := view.start(); start < n {
+			n = start
+		}
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	if d, ok := buf.mem.(Discardable); ok {
+		d.Discard(n)
+	} else if err := moveMemory(buf.mem, n, buf.size-n); err != nil {
+		return err
+	}
+
+	buf.size -= n
+	buf.discarded += n
+	buf.Peeker.index -= n
+	buf.Writer.index -= n
+	if buf.Writer.index < 0 {
+		buf.Writer.index = 0
+	}
+	buf.Peeker.mark -= n
+	buf.Writer.mark -= n
+	buf.markSize -= n
+	return nil
+}
+
+// seekIndex resolve offset relative to whence, result must be within [0, Size()]
+func (buf *Buffer) seekIndex(current int, offset int64, whence int) (int, error) {
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = int64(current)
+	case io.SeekEnd:
+		base = int64(buf.size)
+	default:
+		return 0, ErrInvalidSeek
+	}
+
+	index := base + offset
+	if index < 0 || index > int64(buf.size) {
+		return 0, ErrInvalidSeek
+	}
+	return int(index), nil
+}
+
+// Seek implements io.Seeker, moves reader index. io.SeekCurrent is relative to reader index and io.SeekEnd to Size
+func (buf *Buffer) Seek(offset int64, whence int) (int64, error) {
+	index, err := buf.seekIndex(buf.ReaderIndex(), offset, whence)
+	if err != nil {
+		return 0, err
+	}
+	buf.Peeker.index = index
+	return int64(index), nil
+}
+
+// SeekWriter moves writer index. io.SeekCurrent is relative to writer index and io.SeekEnd to Size
+func (buf *Buffer) SeekWriter(offset int64, whence int) (int64, error) {
+	index, err := buf.seekIndex(buf.WriterIndex(), offset, whence)
+	if err != nil {
+		return 0, err
+	}
+	buf.Writer.index = index
+	return int64(index), nil
+}
+
+// ReadAt implements io.ReaderAt, reader index is not changed
+func (buf *Buffer) ReadAt(dst []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, newError("ReadAt", int(off), len(dst), 0, ErrNegativeOffset)
+	}
+	if off >= int64(buf.size) {
+		if len(dst) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+
+	n, err = buf.PeekAt(int(off), dst)
+	if err == nil && n < len(dst) {
+		err = io.EOF
+	}
+	return
+}
+
+// WriteAt implements io.WriterAt, writer index is not changed. writing past Size fills the gap with zeros
+func (buf *Buffer) WriteAt(src []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, newError("WriteAt", int(off), len(src), 0, ErrNegativeOffset)
+	}
+	if buf.readOnly {
+		return 0, newError("WriteAt", int(off), len(src), 0, ErrReadOnly)
+	}
+
+	if gap := int(off) - buf.size; gap > 0 {
+		if gap > moveChunkSize {
+			gap = moveChunkSize
+		}
+		zeros := make([]byte, gap)
+		for buf.size < int(off) {
+			if remain := int(off) - buf.size; remain < len(zeros) {
+				zeros = zeros[:remain]
+			}
+			if err = buf.writeAt(buf.size, zeros); err != nil {
+				return 0, newError("WriteAt", buf.size, len(zeros), 0, err)
+			}
+		}
+	}
+
+	if err = buf.writeAt(int(off), src); err != nil {
+		return 0, newError("WriteAt", int(off), len(src), 0, err)
+	}
+	return len(src), nil
+}
+
+// ReadFrom implements io.ReaderFrom, reads until io.EOF and writes at writer index in chunks
+func (buf *Buffer) ReadFrom(r io.Reader) (n int64, err error) {
+	chunk := make([]byte, moveChunkSize)
+	for {
+		read, rerr := r.Read(chunk)
+		if read > 0 {
+			if _, err = buf.Writer.Write(chunk[:read]); err != nil {
+				return n, err
+			}
+			n += int64(read)
+		}
+
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
+
+// WriteTo implements io.WriterTo, writes unread bytes in chunks and advances reader index
+func (buf *Buffer) WriteTo(w io.Writer) (n int64, err error) {
+	chunk := make([]byte, moveChunkSize)
+	for buf.Available() > 0 {
+		if remain := buf.Available(); remain < len(chunk) {
+			chunk = chunk[:remain]
+		}
+		if err = buf.mem.Read(buf.ReaderIndex(), chunk); err != nil {
+			return n, err
+		}
+
+		written, werr := w.Write(chunk)
+		buf.SkipRead(written)
+		n += int64(written)
+		if werr != nil {
+			return n, werr
+		}
+		if written < len(chunk) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// readerSkipped consume bytes before reader index and reader mark
+func (buf *Buffer) readerSkipped(index int) {
+	c, ok := buf.mem.(Consumable)
+	if !ok {
+		return
+	}
+	if buf.Peeker.marked && buf.Peeker.mark < index {
+		index = buf.Peeker.mark
+	}
+	c.Consume(index)
+}
+
+// ReadSegments next n bytes as slices of memory without copying, reader index is advanced.
+// slices are only valid until next write, memory which is not Segmented is copied into one slice
+func (buf *Buffer) ReadSegments(n int) ([][]byte, error) {
+	if err := buf.checkAvailable("ReadSegments", n); err != nil {
+		return nil, err
+	}
+
+	var segments [][]byte
+	if s, ok := buf.mem.(Segmented); ok {
+		segments = s.Segments(nil, buf.ReaderIndex(), n)
+	} else {
+		b := make([]byte, n)
+		if err := buf.mem.Read(buf.ReaderIndex(), b); err != nil {
+			return nil, newError("ReadSegments", buf.ReaderIndex(), n, 0, err)
+		}
+		segments = [][]byte{b}
+	}
+
+	buf.SkipRead(n)
+	return segments, nil
+}
+
+// checkAvailable check n bytes can be read for operation op.
+// fails with ErrOutOfRange if n is negative, io.EOF if there is nothing to read and ErrShortBuffer if there is less than n bytes
+func (buf *Buffer) checkAvailable(op string, n int) error {
+	available := buf.Available()
+	if available < 0 {
+		available = 0
+	}
+
+	switch {
+	case n < 0:
+		return newError(op, buf.ReaderIndex(), n, available, ErrOutOfRange)
+	case available >= n:
+		return nil
+	case available == 0:
+		return newError(op, buf.ReaderIndex(), n, 0, io.EOF)
+	default:
+		return newError(op, buf.ReaderIndex(), n, available, ErrShortBuffer)
+	}
+}
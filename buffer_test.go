@@ -1,6 +1,11 @@
 package gobuf
 
 import (
+	"bytes"
+	"io"
+	"strings"
+	"testing/iotest"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -78,4 +83,87 @@ var _ = Describe("Buffer", func() {
 		Expect(buf.Size()).To(Equal(20))
 		Expect(buf.ReaderIndex()).To(Equal(20))
 	})
+
+	It("should seek reader and writer separately", func() {
+		buf := New(nil, WithAutoGrowMemory(FixedGrow(5)))
+		_, _ = buf.Write([]byte("0123456789"))
+
+		pos, err := buf.Seek(3, io.SeekStart)
+		Expect(err).To(BeNil())
+		Expect(pos).To(Equal(int64(3)))
+		pos, err = buf.Seek(2, io.SeekCurrent)
+		Expect(err).To(BeNil())
+		Expect(pos).To(Equal(int64(5)))
+		pos, err = buf.Seek(-1, io.SeekEnd)
+		Expect(err).To(BeNil())
+		Expect(pos).To(Equal(int64(9)))
+		Expect(buf.WriterIndex()).To(Equal(10))
+
+		pos, err = buf.SeekWriter(-4, io.SeekCurrent)
+		Expect(err).To(BeNil())
+		Expect(pos).To(Equal(int64(6)))
+		Expect(buf.ReaderIndex()).To(Equal(9))
+		ExpectSizeError(2)(buf.Write([]byte("ab")))
+		Expect(buf.Size()).To(Equal(10))
+
+		_, err = buf.Seek(-1, io.SeekStart)
+		Expect(err).To(Equal(ErrInvalidSeek))
+		_, err = buf.Seek(1, io.SeekEnd)
+		Expect(err).To(Equal(ErrInvalidSeek))
+		_, err = buf.SeekWriter(0, 3)
+		Expect(err).To(Equal(ErrInvalidSeek))
+		Expect(buf.ReaderIndex()).To(Equal(9))
+
+		buf.Seek(0, io.SeekStart)
+		b, err := buf.ReadBytes(10)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(Equal("012345ab89"))
+	})
+
+	It("should read and write at offset", func() {
+		buf := New(nil, WithAutoGrowMemory(FixedGrow(5)))
+		_, _ = buf.Write([]byte("0123456789"))
+
+		b := make([]byte, 4)
+		ExpectSizeError(4)(buf.ReadAt(b, 2))
+		Expect(string(b)).To(Equal("2345"))
+		n, err := buf.ReadAt(b, 8)
+		Expect(n).To(Equal(2))
+		Expect(err).To(Equal(io.EOF))
+		_, err = buf.ReadAt(b, 10)
+		Expect(err).To(Equal(io.EOF))
+		_, err = buf.ReadAt(b, -1)
+		Expect(err).To(MatchError(ErrNegativeOffset))
+
+		ExpectSizeError(2)(buf.WriteAt([]byte("ab"), 1))
+		ExpectSizeError(2)(buf.WriteAt([]byte("cd"), 12))
+		Expect(buf.Size()).To(Equal(14))
+		Expect(buf.WriterIndex()).To(Equal(10))
+		Expect(buf.ReaderIndex()).To(Equal(0))
+		b, err = buf.ReadBytes(14)
+		Expect(err).To(BeNil())
+		Expect(b).To(Equal([]byte("0ab3456789\x00\x00cd")))
+	})
+
+	It("should copy with io.Copy", func() {
+		src := strings.Repeat("0123456789", 1000)
+		buf := New(nil, WithAutoGrowMemory(FixedGrow(5)))
+		_, _ = buf.Write([]byte("head"))
+		n, err := io.Copy(buf, iotest.HalfReader(strings.NewReader(src)))
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(int64(len(src))))
+		Expect(buf.Size()).To(Equal(len(src) + 4))
+		Expect(buf.WriterIndex()).To(Equal(len(src) + 4))
+
+		buf.SkipRead(4)
+		out := &bytes.Buffer{}
+		n, err = io.Copy(out, buf)
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(int64(len(src))))
+		Expect(out.String()).To(Equal(src))
+		Expect(buf.Available()).To(Equal(0))
+
+		_, err = New(make([]byte, 4)).ReadFrom(strings.NewReader("hello"))
+		Expect(err).To(MatchError(ErrOutOfSpace))
+	})
 })
@@ -0,0 +1,706 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joesonw/gobuf/internal/tag"
+)
+
+// annotation comment marking structs to generate methods for
+const annotation = "//gobuf:generate"
+
+type kind int
+
+const (
+	kindNumber kind = iota + 1
+	kindString
+	kindBytes
+	kindByteArray
+	kindSlice
+	kindArray
+	kindStruct
+)
+
+// typ how a field is encoded
+type typ struct {
+	kind kind
+	// goType go source of the type, basic types are normalized (byte to uint8, rune to int32)
+	goType string
+	// basic underlying basic type of numbers and strings, differs from goType for named types
+	basic string
+	wire  string
+	order string
+	len   string
+	max   int
+	elem  *typ
+}
+
+// wireTypes go type values of wire types are read and written as
+var wireTypes = map[string]string{
+	"bool":    "bool",
+	"u8":      "uint8",
+	"u16":     "uint16",
+	"u32":     "uint32",
+	"u64":     "uint64",
+	"i8":      "int8",
+	"i16":     "int16",
+	"i32":     "int32",
+	"i64":     "int64",
+	"f32":     "float32",
+	"f64":     "float64",
+	"uvarint": "uint64",
+	"varint":  "int64",
+}
+
+// wireMethods suffix of Writer/Reader methods of wire types
+var wireMethods = map[string]string{
+	"bool":    "Bool",
+	"u8":      "Uint8",
+	"u16":     "Uint16",
+	"u32":     "Uint32",
+	"u64":     "Uint64",
+	"i8":      "Int8",
+	"i16":     "Int16",
+	"i32":     "Int32",
+	"i64":     "Int64",
+	"f32":     "Float32",
+	"f64":     "Float64",
+	"uvarint": "Uvarint",
+	"varint":  "Varint",
+}
+
+var wireSizes = map[string]int{
+	"bool": 1,
+	"u8":   1,
+	"u16":  2,
+	"u32":  4,
+	"u64":  8,
+	"i8":   1,
+	"i16":  2,
+	"i32":  4,
+	"i64":  8,
+	"f32":  4,
+	"f64":  8,
+}
+
+var lenPrefixes = map[string]string{
+	"u8":      "gobuf.PrefixUint8",
+	"u16":     "gobuf.PrefixUint16",
+	"u32":     "gobuf.PrefixUint32",
+	"u64":     "gobuf.PrefixUint64",
+	"uvarint": "gobuf.PrefixUvarint",
+}
+
+type field struct {
+	name string
+	typ  *typ
+}
+
+type structType struct {
+	name   string
+	fields []field
+}
+
+// Generate generate methods for annotated structs in go source src, returns nil if there is none
+func Generate(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &resolver{decls: map[string]ast.Expr{}, resolving: map[string]bool{}}
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				r.decls[ts.Name.Name] = ts.Type
+			}
+		}
+	}
+
+	var structs []structType
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if !annotated(ts.Doc) && !(len(gen.Specs) == 1 && annotated(gen.Doc)) {
+				continue
+			}
+
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s is not a struct", fset.Position(ts.Pos()), ts.Name.Name)
+			}
+
+			s, err := r.resolveStruct(fset, ts.Name.Name, st)
+			if err != nil {
+				return nil, err
+			}
+			structs = append(structs, s)
+		}
+	}
+
+	if len(structs) == 0 {
+		return nil, nil
+	}
+
+	g := &generator{}
+	for _, s := range structs {
+		g.generate(s)
+	}
+	return g.source(file.Name.Name)
+}
+
+func annotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// resolver resolves encoding of types, named types declared in the file are resolved to their underlying type
+type resolver struct {
+	decls     map[string]ast.Expr
+	resolving map[string]bool
+}
+
+func (r *resolver) resolveStruct(fset *token.FileSet, name string, st *ast.StructType) (structType, error) {
+	s := structType{name: name}
+	for _, f := range st.Fields.List {
+		t := tag.Tag{Len: tag.DefaultLen, Max: tag.DefaultMax}
+		if f.Tag != nil {
+			raw, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return s, fmt.Errorf("%s: %v", fset.Position(f.Pos()), err)
+			}
+			if t, err = tag.Parse(reflect.StructTag(raw).Get("gobuf")); err != nil {
+				return s, fmt.Errorf("%s: %v", fset.Position(f.Pos()), err)
+			}
+		}
+		if t.Skip {
+			continue
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			if ast.IsExported(n.Name) {
+				names = append(names, n.Name)
+			}
+		}
+		if len(f.Names) == 0 {
+			// embedded field is named after its type
+			expr := f.Type
+			if star, ok := expr.(*ast.StarExpr); ok {
+				expr = star.X
+			}
+			name := types.ExprString(expr)
+			if name = name[strings.LastIndex(name, ".")+1:]; ast.IsExported(name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		resolved, err := r.resolve(f.Type, t)
+		if err != nil {
+			return s, fmt.Errorf("%s: field %s: %v", fset.Position(f.Pos()), strings.Join(names, ", "), err)
+		}
+
+		for _, n := range names {
+			s.fields = append(s.fields, field{name: n, typ: resolved})
+		}
+	}
+	return s, nil
+}
+
+// resolve resolve encoding of type expression with tag
+func (r *resolver) resolve(expr ast.Expr, t tag.Tag) (*typ, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name := e.Name
+		switch name {
+		case "byte":
+			name = "uint8"
+		case "rune":
+			name = "int32"
+		}
+
+		if wire := tag.DefaultWire(name); wire != "" {
+			return resolveNumber(name, wire, t)
+		}
+		if decl, ok := r.decls[name]; ok {
+			return r.resolveNamed(name, decl, t)
+		}
+		if name != "string" {
+			return nil, fmt.Errorf("unknown type %s, types must be builtin or declared in the same file", name)
+		}
+		if t.Wire != "" {
+			return nil, fmt.Errorf("wire type %s does not match %s", t.Wire, name)
+		}
+		return &typ{kind: kindString, goType: name, basic: name, len: t.Len, max: t.Max}, nil
+	case *ast.SelectorExpr:
+		return nil, fmt.Errorf("unknown type %s, types must be builtin or declared in the same file", types.ExprString(e))
+	case *ast.ArrayType:
+		elemIsByte := false
+		if ident, ok := e.Elt.(*ast.Ident); ok {
+			elemIsByte = (ident.Name == "byte" || ident.Name == "uint8") && t.Wire == ""
+		}
+
+		if e.Len == nil && elemIsByte {
+			return &typ{kind: kindBytes, goType: types.ExprString(e), len: t.Len, max: t.Max}, nil
+		}
+		if e.Len != nil && elemIsByte {
+			return &typ{kind: kindByteArray, goType: types.ExprString(e)}, nil
+		}
+
+		elem, err := r.resolve(e.Elt, t)
+		if err != nil {
+			return nil, err
+		}
+		if e.Len == nil {
+			return &typ{kind: kindSlice, goType: types.ExprString(e), len: t.Len, max: t.Max, elem: elem}, nil
+		}
+		return &typ{kind: kindArray, goType: types.ExprString(e), elem: elem}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", types.ExprString(expr))
+	}
+}
+
+// resolveNamed resolve named type declared in the file as decl by its underlying type
+func (r *resolver) resolveNamed(name string, decl ast.Expr, t tag.Tag) (*typ, error) {
+	if _, ok := decl.(*ast.StructType); ok {
+		if t.Wire != "" {
+			return nil, fmt.Errorf("wire type %s does not match %s", t.Wire, name)
+		}
+		return &typ{kind: kindStruct, goType: name}, nil
+	}
+	if r.resolving[name] {
+		return nil, fmt.Errorf("recursive type %s", name)
+	}
+
+	r.resolving[name] = true
+	underlying, err := r.resolve(decl, t)
+	delete(r.resolving, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if underlying.kind == kindStruct {
+		return nil, fmt.Errorf("unsupported type %s, defined types of structs have no generated methods", name)
+	}
+
+	named := *underlying
+	named.goType = name
+	return &named, nil
+}
+
+func resolveNumber(name, wire string, t tag.Tag) (*typ, error) {
+	if t.Wire != "" {
+		wire = t.Wire
+	}
+
+	var valid bool
+	switch wire {
+	case "bool":
+		valid = name == "bool"
+	case "f32", "f64":
+		valid = name == "float32" || name == "float64"
+	default:
+		_, _, valid = intInfo(name)
+	}
+	if !valid {
+		return nil, fmt.Errorf("wire type %s does not match %s", wire, name)
+	}
+
+	return &typ{kind: kindNumber, goType: name, basic: name, wire: wire, order: t.Order}, nil
+}
+
+// intInfo bits and signedness of integer type, int and uint are treated as 64 bits
+func intInfo(name string) (bits int, signed bool, ok bool) {
+	switch name {
+	case "int8":
+		return 8, true, true
+	case "int16":
+		return 16, true, true
+	case "int32":
+		return 32, true, true
+	case "int", "int64":
+		return 64, true, true
+	case "uint8":
+		return 8, false, true
+	case "uint16":
+		return 16, false, true
+	case "uint32":
+		return 32, false, true
+	case "uint", "uint64":
+		return 64, false, true
+	}
+	return 0, false, false
+}
+
+// rangeCheck condition when expr of type src does not fit in type dst, empty if it always fits
+func rangeCheck(src, dst, expr string) string {
+	sb, ss, ok := intInfo(src)
+	if !ok {
+		return ""
+	}
+	db, ds, ok := intInfo(dst)
+	if !ok {
+		return ""
+	}
+
+	maxUint := fmt.Sprintf("math.MaxUint%d", db)
+	maxInt := fmt.Sprintf("math.MaxInt%d", db)
+	minInt := fmt.Sprintf("math.MinInt%d", db)
+
+	switch {
+	case ss && !ds:
+		if sb-1 > db {
+			return fmt.Sprintf("%s < 0 || uint64(%s) > %s", expr, expr, maxUint)
+		}
+		return fmt.Sprintf("%s < 0", expr)
+	case !ss && !ds && sb > db:
+		return fmt.Sprintf("uint64(%s) > %s", expr, maxUint)
+	case ss && ds && sb > db:
+		return fmt.Sprintf("int64(%s) < %s || int64(%s) > %s", expr, minInt, expr, maxInt)
+	case !ss && ds && sb >= db:
+		return fmt.Sprintf("uint64(%s) > %s", expr, maxInt)
+	}
+	return ""
+}
+
+// convert convert expr of type from to type to
+func convert(expr, from, to string) string {
+	if from == to {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+// fixedSize encoded size of type if it does not depend on value
+func fixedSize(t *typ) (string, bool) {
+	switch t.kind {
+	case kindNumber:
+		size, ok := wireSizes[t.wire]
+		return strconv.Itoa(size), ok
+	case kindArray:
+		size, ok := fixedSize(t.elem)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("len(%s{})*%s", t.goType, size), true
+	}
+	return "", false
+}
+
+type generator struct {
+	buf bytes.Buffer
+}
+
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// check return err if it is not nil
+func (g *generator) check(call string) {
+	g.p("if err := %s; err != nil {", call)
+	g.p("return err")
+	g.p("}")
+}
+
+func (g *generator) source(pkg string) ([]byte, error) {
+	body := g.buf.String()
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gobufgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"encoding/binary", "math"} {
+		name := imp[strings.LastIndex(imp, "/")+1:]
+		if strings.Contains(body, name+".") {
+			fmt.Fprintf(&out, "%q\n", imp)
+		}
+	}
+	fmt.Fprintf(&out, "\n%q\n)\n%s", "github.com/joesonw/gobuf", body)
+
+	return format.Source(out.Bytes())
+}
+
+func (g *generator) generate(s structType) {
+	g.p("")
+	g.p("// MarshalGobuf write %s into w", s.name)
+	g.p("func (x *%s) MarshalGobuf(w *gobuf.Writer) error {", s.name)
+	for _, f := range s.fields {
+		g.marshal("x."+f.name, f.typ, 0)
+	}
+	g.p("return nil")
+	g.p("}")
+
+	g.p("")
+	g.p("// UnmarshalGobuf read %s from r", s.name)
+	g.p("func (x *%s) UnmarshalGobuf(r *gobuf.Reader) error {", s.name)
+	for _, f := range s.fields {
+		g.unmarshal("x."+f.name, f.typ, 0)
+	}
+	g.p("return nil")
+	g.p("}")
+
+	g.p("")
+	g.p("// SizeGobuf number of bytes %s takes when marshalled", s.name)
+	g.p("func (x *%s) SizeGobuf() int {", s.name)
+	g.p("n := 0")
+	for _, f := range s.fields {
+		g.size("x."+f.name, f.typ, 0)
+	}
+	g.p("return n")
+	g.p("}")
+}
+
+// orderType encoding/binary byte order of order in tag
+func orderType(order string) string {
+	if order == "be" {
+		return "binary.BigEndian"
+	}
+	return "binary.LittleEndian"
+}
+
+func (g *generator) marshal(expr string, t *typ, depth int) {
+	switch t.kind {
+	case kindNumber:
+		g.marshalNumber(expr, t)
+	case kindString:
+		g.check(fmt.Sprintf("w.WriteLengthPrefixedString(%s, %s)", lenPrefixes[t.len], convert(expr, t.goType, "string")))
+	case kindBytes:
+		g.check(fmt.Sprintf("w.WriteLengthPrefixed(%s, %s)", lenPrefixes[t.len], expr))
+	case kindByteArray:
+		g.check(fmt.Sprintf("w.WriteBytes(%s[:])", expr))
+	case kindSlice:
+		g.marshalLength(fmt.Sprintf("len(%s)", expr), t.len)
+		g.marshalElements(expr, t, depth)
+	case kindArray:
+		g.marshalElements(expr, t, depth)
+	case kindStruct:
+		g.check(fmt.Sprintf("%s.MarshalGobuf(w)", expr))
+	}
+}
+
+func (g *generator) marshalElements(expr string, t *typ, depth int) {
+	i := fmt.Sprintf("i%d", depth)
+	g.p("for %s := range %s {", i, expr)
+	g.marshal(fmt.Sprintf("%s[%s]", expr, i), t.elem, depth+1)
+	g.p("}")
+}
+
+func (g *generator) marshalLength(expr, prefix string) {
+	switch prefix {
+	case "u8", "u16", "u32":
+		method := wireMethods[prefix]
+		g.p("if uint64(%s) > math.Max%s {", expr, method)
+		g.p("return gobuf.ErrTooLarge")
+		g.p("}")
+		g.check(fmt.Sprintf("w.Write%s(%s(%s))", method, wireTypes[prefix], expr))
+	case "u64":
+		g.check(fmt.Sprintf("w.WriteUint64(uint64(%s))", expr))
+	default:
+		g.check(fmt.Sprintf("w.WriteUvarint(uint64(%s))", expr))
+	}
+}
+
+func (g *generator) marshalNumber(expr string, t *typ) {
+	wireType := wireTypes[t.wire]
+	if cond := rangeCheck(t.basic, wireType, expr); cond != "" {
+		g.p("if %s {", cond)
+		g.p("return gobuf.ErrOutOfRange")
+		g.p("}")
+	}
+
+	size := wireSizes[t.wire]
+	if t.order == "" || size < 2 {
+		g.check(fmt.Sprintf("w.Write%s(%s)", wireMethods[t.wire], convert(expr, t.goType, wireType)))
+		return
+	}
+
+	// encode with order of field regardless of order of writer
+	bitsType := fmt.Sprintf("uint%d", size*8)
+	value := convert(expr, t.goType, bitsType)
+	switch t.wire {
+	case "f32":
+		value = fmt.Sprintf("math.Float32bits(%s)", convert(expr, t.goType, "float32"))
+	case "f64":
+		value = fmt.Sprintf("math.Float64bits(%s)", convert(expr, t.goType, "float64"))
+	}
+
+	g.p("{")
+	g.p("var b [%d]byte", size)
+	g.p("%s.PutUint%d(b[:], %s)", orderType(t.order), size*8, value)
+	g.check("w.WriteBytes(b[:])")
+	g.p("}")
+}
+
+func (g *generator) unmarshal(expr string, t *typ, depth int) {
+	switch t.kind {
+	case kindNumber:
+		g.unmarshalNumber(expr, t)
+	case kindString:
+		g.p("{")
+		g.p("s, err := r.ReadLengthPrefixedString(%s, %d)", lenPrefixes[t.len], t.max)
+		g.p("if err != nil {")
+		g.p("return err")
+		g.p("}")
+		g.p("%s = %s", expr, convert("s", "string", t.goType))
+		g.p("}")
+	case kindBytes:
+		g.p("{")
+		g.p("b, err := r.ReadLengthPrefixed(%s, %d)", lenPrefixes[t.len], t.max)
+		g.p("if err != nil {")
+		g.p("return err")
+		g.p("}")
+		g.p("%s = b", expr)
+		g.p("}")
+	case kindByteArray:
+		g.p("{")
+		g.p("b, err := r.ReadBytes(len(%s))", expr)
+		g.p("if err != nil {")
+		g.p("return err")
+		g.p("}")
+		g.p("copy(%s[:], b)", expr)
+		g.p("}")
+	case kindSlice:
+		g.p("{")
+		g.p("n, err := r.Read%s()", wireMethods[t.len])
+		g.p("if err != nil {")
+		g.p("return err")
+		g.p("}")
+		g.p("if uint64(n) > %d {", t.max)
+		g.p("return gobuf.ErrTooLarge")
+		g.p("}")
+		// elements are appended as they are read, claimed length is not allocated up front
+		g.p("c := int(n)")
+		g.p("if a := r.Available(); c > a {")
+		g.p("c = a")
+		g.p("}")
+		g.p("%s = make(%s, 0, c)", expr, t.goType)
+		i := fmt.Sprintf("i%d", depth)
+		e := fmt.Sprintf("e%d", depth)
+		g.p("for %s := 0; %s < int(n); %s++ {", i, i, i)
+		g.p("var %s %s", e, t.elem.goType)
+		g.unmarshal(e, t.elem, depth+1)
+		g.p("%s = append(%s, %s)", expr, expr, e)
+		g.p("}")
+		g.p("}")
+	case kindArray:
+		g.unmarshalElements(expr, t, depth)
+	case kindStruct:
+		g.check(fmt.Sprintf("%s.UnmarshalGobuf(r)", expr))
+	}
+}
+
+func (g *generator) unmarshalElements(expr string, t *typ, depth int) {
+	i := fmt.Sprintf("i%d", depth)
+	g.p("for %s := range %s {", i, expr)
+	g.unmarshal(fmt.Sprintf("%s[%s]", expr, i), t.elem, depth+1)
+	g.p("}")
+}
+
+func (g *generator) unmarshalNumber(expr string, t *typ) {
+	wireType := wireTypes[t.wire]
+	size := wireSizes[t.wire]
+
+	g.p("{")
+	if t.order == "" || size < 2 {
+		g.p("v, err := r.Read%s()", wireMethods[t.wire])
+		g.p("if err != nil {")
+		g.p("return err")
+		g.p("}")
+	} else {
+		// decode with order of field regardless of order of reader
+		g.p("b, err := r.ReadBytes(%d)", size)
+		g.p("if err != nil {")
+		g.p("return err")
+		g.p("}")
+		g.p("u := %s.Uint%d(b)", orderType(t.order), size*8)
+		switch t.wire {
+		case "f32":
+			g.p("v := math.Float32frombits(u)")
+		case "f64":
+			g.p("v := math.Float64frombits(u)")
+		default:
+			g.p("v := %s", convert("u", fmt.Sprintf("uint%d", size*8), wireType))
+		}
+	}
+
+	if cond := rangeCheck(wireType, t.basic, "v"); cond != "" {
+		g.p("if %s {", cond)
+		g.p("return gobuf.ErrOutOfRange")
+		g.p("}")
+	}
+	g.p("%s = %s", expr, convert("v", wireType, t.goType))
+	g.p("}")
+}
+
+func (g *generator) size(expr string, t *typ, depth int) {
+	if size, ok := fixedSize(t); ok {
+		g.p("n += %s", size)
+		return
+	}
+
+	switch t.kind {
+	case kindNumber:
+		if t.wire == "uvarint" {
+			g.p("n += gobuf.UvarintSize(uint64(%s))", expr)
+		} else {
+			g.p("n += gobuf.VarintSize(int64(%s))", expr)
+		}
+	case kindString, kindBytes:
+		g.sizeLength(fmt.Sprintf("len(%s)", expr), t.len)
+		g.p("n += len(%s)", expr)
+	case kindByteArray:
+		g.p("n += len(%s)", expr)
+	case kindSlice:
+		g.sizeLength(fmt.Sprintf("len(%s)", expr), t.len)
+		g.sizeElements(expr, t, depth)
+	case kindArray:
+		g.sizeElements(expr, t, depth)
+	case kindStruct:
+		g.p("n += %s.SizeGobuf()", expr)
+	}
+}
+
+func (g *generator) sizeElements(expr string, t *typ, depth int) {
+	if size, ok := fixedSize(t.elem); ok {
+		g.p("n += len(%s) * %s", expr, size)
+		return
+	}
+
+	i := fmt.Sprintf("i%d", depth)
+	g.p("for %s := range %s {", i, expr)
+	g.size(fmt.Sprintf("%s[%s]", expr, i), t.elem, depth+1)
+	g.p("}")
+}
+
+func (g *generator) sizeLength(expr, prefix string) {
+	if prefix == "uvarint" {
+		g.p("n += gobuf.UvarintSize(uint64(%s))", expr)
+		return
+	}
+	g.p("n += %d", map[string]int{"u8": 1, "u16": 2, "u32": 4, "u64": 8}[prefix])
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gobufgen")
+}
+
+var update = flag.Bool("update", false, "update golden files")
+
+var _ = Describe("Generate", func() {
+	It("should match golden files", func() {
+		inputs, err := filepath.Glob("testdata/*.go")
+		Expect(err).To(BeNil())
+		Expect(inputs).NotTo(BeEmpty())
+
+		for _, input := range inputs {
+			src, err := os.ReadFile(input)
+			Expect(err).To(BeNil())
+			generated, err := Generate(input, src)
+			Expect(err).To(BeNil())
+
+			golden := strings.TrimSuffix(input, ".go") + ".golden"
+			if *update {
+				Expect(os.WriteFile(golden, generated, 0644)).To(BeNil())
+			}
+			expected, err := os.ReadFile(golden)
+			Expect(err).To(BeNil())
+			Expect(string(generated)).To(Equal(string(expected)), input)
+		}
+	})
+
+	It("should match checked in example", func() {
+		src, err := os.ReadFile("internal/example/example.go")
+		Expect(err).To(BeNil())
+		generated, err := Generate("example.go", src)
+		Expect(err).To(BeNil())
+		expected, err := os.ReadFile("internal/example/example_gobuf.go")
+		Expect(err).To(BeNil())
+		Expect(string(generated)).To(Equal(string(expected)))
+	})
+
+	It("should return nil without annotated structs", func() {
+		generated, err := Generate("x.go", []byte("package x\n\ntype T struct{ V int }\n"))
+		Expect(err).To(BeNil())
+		Expect(generated).To(BeNil())
+	})
+
+	It("should reject invalid tags and types", func() {
+		_, err := Generate("x.go", []byte("package x\n\n//gobuf:generate\ntype T struct{ V int `gobuf:\"u17\"` }\n"))
+		Expect(err).NotTo(BeNil())
+		_, err = Generate("x.go", []byte("package x\n\n//gobuf:generate\ntype T struct{ V string `gobuf:\"f32\"` }\n"))
+		Expect(err).NotTo(BeNil())
+		_, err = Generate("x.go", []byte("package x\n\n//gobuf:generate\ntype T struct{ V map[string]int }\n"))
+		Expect(err).NotTo(BeNil())
+		_, err = Generate("x.go", []byte("package x\n\n//gobuf:generate\ntype T int\n"))
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should reject named types without encoding", func() {
+		_, err := Generate("x.go", []byte("package x\n\ntype C string\n\n//gobuf:generate\ntype T struct{ V C `gobuf:\"u16\"` }\n"))
+		Expect(err).To(MatchError(ContainSubstring("wire type u16 does not match string")))
+		_, err = Generate("x.go", []byte("package x\n\ntype L []L\n\n//gobuf:generate\ntype T struct{ V L }\n"))
+		Expect(err).To(MatchError(ContainSubstring("recursive type L")))
+		_, err = Generate("x.go", []byte("package x\n\ntype P struct{}\n\ntype Q P\n\n//gobuf:generate\ntype T struct{ V Q }\n"))
+		Expect(err).To(MatchError(ContainSubstring("unsupported type Q")))
+		_, err = Generate("x.go", []byte("package x\n\ntype M map[string]int\n\n//gobuf:generate\ntype T struct{ V M }\n"))
+		Expect(err).To(MatchError(ContainSubstring("unsupported type map[string]int")))
+		_, err = Generate("x.go", []byte("package x\n\n//gobuf:generate\ntype T struct{ V Code }\n"))
+		Expect(err).To(MatchError("x.go:4:16: field V: unknown type Code, types must be builtin or declared in the same file"))
+		_, err = Generate("x.go", []byte("package x\n\nimport \"time\"\n\n//gobuf:generate\ntype T struct{ time.Duration }\n"))
+		Expect(err).To(MatchError(ContainSubstring("field Duration: unknown type time.Duration")))
+		_, err = Generate("x.go", []byte("package x\n\nimport \"time\"\n\n//gobuf:generate\ntype T struct{ V int; t time.Time }\n"))
+		Expect(err).To(BeNil())
+	})
+})
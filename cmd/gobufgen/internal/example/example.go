@@ -0,0 +1,23 @@
+// Package example shows code generated by gobufgen, and checks it against reflection based gobuf.Marshal
+package example
+
+//go:generate go run github.com/joesonw/gobuf/cmd/gobufgen example.go
+
+//gobuf:generate
+type Point struct {
+	X int32 `gobuf:"varint"`
+	Y int32 `gobuf:"varint"`
+}
+
+//gobuf:generate
+type Shape struct {
+	Kind   uint8
+	ID     [4]byte
+	Name   string  `gobuf:"len=u8,max=16"`
+	Scale  float32 `gobuf:"f32,be"`
+	Area   int     `gobuf:"u32,le"`
+	Points []Point `gobuf:"len=u16"`
+	Labels []string
+	Data   []byte
+	Hidden bool `gobuf:"-"`
+}
@@ -0,0 +1,64 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/joesonw/gobuf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "example")
+}
+
+var _ = Describe("Generated", func() {
+	shape := Shape{
+		Kind:   3,
+		ID:     [4]byte{1, 2, 3, 4},
+		Name:   "triangle",
+		Scale:  1.5,
+		Area:   300,
+		Points: []Point{{X: 0, Y: 0}, {X: -1, Y: 70}, {X: 1 << 20, Y: -1 << 20}},
+		Labels: []string{"a", "", "bc"},
+		Data:   []byte{0xff},
+	}
+
+	It("should encode the same as reflection", func() {
+		for _, options := range [][]gobuf.OptionFunc{nil, {gobuf.WithBigEndian()}} {
+			generated := gobuf.New(nil, append(options, gobuf.WithAutoGrowMemory(gobuf.FixedGrow(32)))...)
+			Expect(gobuf.Marshal(generated.Writer, &shape)).To(BeNil())
+
+			reflected := gobuf.New(nil, append(options, gobuf.WithAutoGrowMemory(gobuf.FixedGrow(32)))...)
+			Expect(gobuf.Marshal(reflected.Writer, shape)).To(BeNil())
+
+			Expect(generated.Bytes()[:generated.Size()]).To(Equal(reflected.Bytes()[:reflected.Size()]))
+			Expect(shape.SizeGobuf()).To(Equal(generated.Size()))
+
+			var out Shape
+			Expect(gobuf.Unmarshal(generated.Reader, &out)).To(BeNil())
+			Expect(out).To(Equal(shape))
+			Expect(generated.Available()).To(Equal(0))
+		}
+	})
+
+	It("should reject values out of range", func() {
+		b := gobuf.New(nil, gobuf.WithAutoGrowMemory(gobuf.FixedGrow(32)))
+		s := shape
+		s.Area = -1
+		Expect(s.MarshalGobuf(b.Writer)).To(Equal(gobuf.ErrOutOfRange))
+
+		s = shape
+		s.Points = make([]Point, 1<<16)
+		Expect(s.MarshalGobuf(b.Writer)).To(Equal(gobuf.ErrTooLarge))
+	})
+
+	It("should limit length when unmarshalling", func() {
+		b := gobuf.New(nil, gobuf.WithAutoGrowMemory(gobuf.FixedGrow(32)))
+		s := shape
+		s.Name = "longer than sixteen"
+		Expect(gobuf.Marshal(b.Writer, s)).To(BeNil())
+		Expect(gobuf.Unmarshal(b.Reader, &Shape{})).To(MatchError(gobuf.ErrTooLarge))
+	})
+})
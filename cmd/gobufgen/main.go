@@ -0,0 +1,55 @@
+// Command gobufgen generates MarshalGobuf, UnmarshalGobuf and SizeGobuf methods for structs
+// annotated with //gobuf:generate, using the same `gobuf` tags as gobuf.Marshal.
+//
+//	//go:generate gobufgen $GOFILE
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	output := flag.String("o", "", "output file, <input>_gobuf.go by default")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gobufgen [-o output] file.go...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || (*output != "" && flag.NArg() > 1) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	for _, input := range flag.Args() {
+		out := *output
+		if out == "" {
+			out = strings.TrimSuffix(input, ".go") + "_gobuf.go"
+		}
+
+		if err := run(input, out); err != nil {
+			fmt.Fprintf(os.Stderr, "gobufgen: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func run(input, output string) error {
+	src, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	generated, err := Generate(input, src)
+	if err != nil {
+		return err
+	}
+	if generated == nil {
+		return fmt.Errorf("%s: no struct annotated with %s", input, annotation)
+	}
+
+	return os.WriteFile(output, generated, 0644)
+}
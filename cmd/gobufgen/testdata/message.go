@@ -0,0 +1,31 @@
+package message
+
+import "time"
+
+//gobuf:generate
+type Header struct {
+	Magic   uint16 `gobuf:"u16,be"`
+	Version byte
+	Flags   [2]bool
+}
+
+//gobuf:generate
+type Message struct {
+	Header
+	Length  int     `gobuf:"u32"`
+	Offset  int64   `gobuf:"i16,le"`
+	Ratio   float32 `gobuf:"f32,be"`
+	Score   float64
+	ID      [4]byte
+	Payload []byte   `gobuf:"len=u16"`
+	Name    string   `gobuf:"len=u8,max=8"`
+	Counts  []uint16 `gobuf:"u16,be,len=u8"`
+	Tags    [][]int  `gobuf:"max=4"`
+	Items   []Header
+	Skipped time.Time `gobuf:"-"`
+	hidden  int
+}
+
+type ignored struct {
+	V int
+}
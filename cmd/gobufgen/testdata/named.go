@@ -0,0 +1,22 @@
+package named
+
+type Code uint16
+
+type Name string
+
+type Raw []byte
+
+type IDs []Code
+
+type Point struct {
+	X, Y int32
+}
+
+//gobuf:generate
+type Record struct {
+	Code   Code `gobuf:"u16,be"`
+	Name   Name `gobuf:"len=u8,max=16"`
+	Raw    Raw
+	IDs    IDs `gobuf:"len=u8,max=8"`
+	Points []Point
+}
@@ -0,0 +1,153 @@
+package gobuf
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// CompositeBuffer read only buffer over an ordered list of components sharing memory without copying,
+// reads can cross component boundaries
+type CompositeBuffer struct {
+	*Peeker
+	*Reader
+	components []*windowMemory
+	size       int
+	order      binary.ByteOrder
+}
+
+func NewCompositeBuffer(order binary.ByteOrder) *CompositeBuffer {
+	c := &CompositeBuffer{
+		order: order,
+	}
+	c.Peeker = NewPeeker(c)
+	c.Reader = NewRead(c, c.Peeker)
+	return c
+}
+
+// AddBuffer append unread bytes of b as a component, b is not read.
+// bytes of b from its reader index on are not discarded until component is removed
+func (c *CompositeBuffer) AddBuffer(b *Buffer) error {
+	c.components = append(c.components, b.pin(b.ReaderIndex(), b.Available()))
+	c.size += b.Available()
+	return nil
+}
+
+// AddMemory append length bytes of mem starts at offset as a component, bytes must be within mem.Length()
+func (c *CompositeBuffer) AddMemory(mem Memory, offset, length int) error {
+	if offset < 0 {
+		return newError("AddMemory", offset, length, 0, ErrNegativeOffset)
+	}
+	if size := mem.Length(); length < 0 || offset > size || length > size-offset {
+		return newError("AddMemory", offset, length, remaining(size, offset), ErrOutOfRange)
+	}
+
+	c.components = append(c.components, &windowMemory{
+		mem:    mem,
+		offset: offset,
+		length: length,
+	})
+	c.size += length
+	return nil
+}
+
+// RemoveComponent remove i-th component, reader index is moved back by bytes read from it
+func (c *CompositeBuffer) RemoveComponent(i int) error {
+	if i < 0 || i >= len(c.components) {
+		return newError("RemoveComponent", i, 1, len(c.components), ErrOutOfRange)
+	}
+
+	start := 0
+	for _, component := range c.components[:i] {
+		start += component.length
+	}
+	length := c.components[i].length
+
+	if read := c.ReaderIndex() - start; read > 0 {
+		if read > length {
+			read = length
+		}
+		c.Peeker.index -= read
+	}
+
+	c.components[i].release()
+	c.components = append(c.components[:i], c.components[i+1:]...)
+	c.size -= length
+	return nil
+}
+
+// NumComponents number of components
+func (c *CompositeBuffer) NumComponents() int {
+	return len(c.components)
+}
+
+func (c *CompositeBuffer) Size() int {
+	return c.size
+}
+
+// peekSize implements sizedPeekable
+func (c *CompositeBuffer) peekSize() int {
+	return c.size
+}
+
+func (c *CompositeBuffer) Order() binary.ByteOrder {
+	return c.order
+}
+
+// PeekAt implements Peekable
+func (c *CompositeBuffer) PeekAt(at int, dst []byte) (n int, err error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if at < 0 {
+		return 0, ErrNegativeOffset
+	}
+	if at >= c.size {
+		return 0, io.EOF
+	}
+
+	start := 0
+	for _, component := range c.components {
+		end := start + component.length
+		if at < end && n < len(dst) {
+			chunk := dst[n:]
+			if remain := end - at; remain < len(chunk) {
+				chunk = chunk[:remain]
+			}
+			if err = component.Read(at-start, chunk); err != nil {
+				return 0, err
+			}
+			n += len(chunk)
+			at += len(chunk)
+		}
+		start = end
+	}
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo, unread bytes of each component are written without flattening
+// (writev on net.Conn) and reader index is advanced
+func (c *CompositeBuffer) WriteTo(w io.Writer) (n int64, err error) {
+	var buffers net.Buffers
+	index := c.ReaderIndex()
+	start := 0
+	for _, component := range c.components {
+		end := start + component.length
+		if index < end {
+			offset := index - start
+			if offset < 0 {
+				offset = 0
+			}
+			buffers = component.Segments(buffers, offset, component.length-offset)
+		}
+		start = end
+	}
+
+	total := int64(c.Available())
+	n, err = buffers.WriteTo(w)
+	c.SkipRead(int(n))
+	if err == nil && n < total {
+		err = io.ErrShortWrite
+	}
+	return
+}
@@ -0,0 +1,100 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompositeBuffer", func() {
+	newComposite := func() (*CompositeBuffer, *Buffer) {
+		header := New(nil, WithAutoGrowMemory(FixedGrow(4)), WithBigEndian())
+		Expect(header.WriteUint16(0xcafe)).To(BeNil())
+		Expect(header.WriteUint8(5)).To(BeNil())
+
+		payload := New(nil, WithLinkedListMemory(FixedGrow(2)))
+		Expect(payload.WriteString("xhello")).To(BeNil())
+		payload.SkipRead(1)
+
+		c := NewCompositeBuffer(binary.BigEndian)
+		Expect(c.AddBuffer(header)).To(BeNil())
+		Expect(c.AddBuffer(payload)).To(BeNil())
+		Expect(c.AddMemory(NewSliceMemory([]byte("--end--"), nil), 2, 3)).To(BeNil())
+		return c, payload
+	}
+
+	It("should read across components", func() {
+		c, payload := newComposite()
+		Expect(c.NumComponents()).To(Equal(3))
+		Expect(c.Size()).To(Equal(11))
+
+		v, err := c.PeekUint32(1)
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint32(0xfe056865)))
+
+		magic, err := c.ReadUint16()
+		Expect(err).To(BeNil())
+		Expect(magic).To(Equal(uint16(0xcafe)))
+		n, err := c.ReadUint8()
+		Expect(err).To(BeNil())
+		s, err := c.ReadString(int(n) + 3)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("helloend"))
+		_, err = c.ReadUint8()
+		Expect(err).To(MatchError(io.EOF))
+		Expect(payload.ReaderIndex()).To(Equal(1))
+	})
+
+	It("should add and remove components", func() {
+		c, _ := newComposite()
+		c.SkipRead(4)
+
+		Expect(c.RemoveComponent(1)).To(BeNil())
+		Expect(c.Size()).To(Equal(6))
+		Expect(c.ReaderIndex()).To(Equal(3))
+		s, err := c.ReadString(3)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("end"))
+
+		Expect(c.RemoveComponent(2)).To(Equal(&Error{Op: "RemoveComponent", Offset: 2, Want: 1, Available: 2, Err: ErrOutOfRange}))
+		Expect(c.RemoveComponent(-1)).To(MatchError(ErrOutOfRange))
+		Expect(c.AddMemory(NewSliceMemory([]byte("!"), nil), 0, 1)).To(BeNil())
+		b, err := c.ReadByte()
+		Expect(err).To(BeNil())
+		Expect(b).To(Equal(byte('!')))
+	})
+
+	It("should reject components outside of memory", func() {
+		c := NewCompositeBuffer(binary.BigEndian)
+		mem := NewSliceMemory([]byte("hello"), nil)
+		Expect(c.AddMemory(mem, -1, 2)).To(Equal(&Error{Op: "AddMemory", Offset: -1, Want: 2, Err: ErrNegativeOffset}))
+		Expect(c.AddMemory(mem, 1, -1)).To(Equal(&Error{Op: "AddMemory", Offset: 1, Want: -1, Available: 4, Err: ErrOutOfRange}))
+		Expect(c.AddMemory(mem, 3, 3)).To(Equal(&Error{Op: "AddMemory", Offset: 3, Want: 3, Available: 2, Err: ErrOutOfRange}))
+		Expect(c.AddMemory(mem, 6, 0)).To(Equal(&Error{Op: "AddMemory", Offset: 6, Want: 0, Available: 0, Err: ErrOutOfRange}))
+		Expect(c.NumComponents()).To(Equal(0))
+
+		Expect(c.AddMemory(mem, 3, 2)).To(BeNil())
+		Expect(c.AddMemory(mem, 5, 0)).To(BeNil())
+		Expect(c.Size()).To(Equal(2))
+	})
+
+	It("should write each component", func() {
+		c, _ := newComposite()
+		c.SkipRead(2)
+
+		out := &countingWriter{}
+		n, err := c.WriteTo(out)
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(int64(9)))
+		Expect(out.Bytes()).To(Equal(append([]byte{5}, "helloend"...)))
+		Expect(out.writes).To(Equal(3))
+		Expect(c.Available()).To(Equal(0))
+
+		n, err = c.WriteTo(&bytes.Buffer{})
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(int64(0)))
+	})
+})
@@ -0,0 +1,108 @@
+package gobuf
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Discard", func() {
+	It("should discard read bytes of slice memory", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)))
+		Expect(b.WriteString("01234567890123456789")).To(BeNil())
+		b.SkipRead(5)
+
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(0))
+		Expect(b.WriterIndex()).To(Equal(15))
+		Expect(b.Size()).To(Equal(15))
+
+		s, err := b.ReadString(15)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("567890123456789"))
+
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(b.Size()).To(Equal(0))
+		Expect(b.WriterIndex()).To(Equal(0))
+		Expect(b.WriteString("abc")).To(BeNil())
+		Expect(string(b.Bytes()[:4])).To(Equal("abc\x00"))
+	})
+
+	It("should move unread bytes of custom memory", func() {
+		m := NewSliceMemory(nil, FixedGrow(8))
+		b := New(nil, WithMemory(struct{ Memory }{m}))
+		Expect(b.WriteString("hello world")).To(BeNil())
+		b.SkipRead(6)
+
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(b.Size()).To(Equal(5))
+
+		s, err := b.ReadString(5)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("world"))
+	})
+
+	It("should release whole nodes of list memory", func() {
+		m := NewListMemory(nil, FixedGrow(4))
+		b := New(nil, WithMemory(m))
+		Expect(b.WriteString("0123456789ab")).To(BeNil())
+		Expect(m.Length()).To(Equal(12))
+		b.SkipRead(6)
+
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(m.Length()).To(Equal(6))
+		Expect(string(m.nodes[0])).To(Equal("6789ab"))
+		Expect(b.Size()).To(Equal(6))
+		Expect(b.WriterIndex()).To(Equal(6))
+
+		Expect(b.WriteString("cd")).To(BeNil())
+		s, err := b.ReadString(8)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("6789abcd"))
+
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(b.Size()).To(Equal(0))
+		Expect(b.WriteString("ef")).To(BeNil())
+		s, err = b.ReadString(2)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("ef"))
+	})
+
+	It("should discard automatically", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)), WithAutoDiscard(4))
+		Expect(b.WriteString("abcdef")).To(BeNil())
+		_, err := b.ReadString(3)
+		Expect(err).To(BeNil())
+
+		Expect(b.WriteString("g")).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(3))
+
+		_, err = b.ReadString(1)
+		Expect(err).To(BeNil())
+		Expect(b.WriteString("h")).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(0))
+		Expect(b.WriterIndex()).To(Equal(4))
+
+		s, err := b.ReadString(4)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("efgh"))
+	})
+
+	It("should fill reservation after discard", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)))
+		Expect(b.WriteString("abc")).To(BeNil())
+		r, err := b.Reserve(1)
+		Expect(err).To(BeNil())
+		Expect(b.WriteString("d")).To(BeNil())
+
+		b.SkipRead(2)
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(r.PutUint8('x')).To(BeNil())
+
+		s, err := b.ReadString(3)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("cxd"))
+
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(r.PutUint8('y')).To(MatchError(ErrDiscarded))
+	})
+})
@@ -1,7 +1,83 @@
 package gobuf
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	gobuftag "github.com/joesonw/gobuf/internal/tag"
+)
 
 var (
-	ErrOutOfSpace = errors.New("not enough space to write")
+	ErrOutOfSpace          = errors.New("not enough space to write")
+	ErrVarintOverflow      = errors.New("varint overflows a 64-bit integer")
+	ErrTooLarge            = errors.New("length exceeds maximum allowed")
+	ErrInvalidPrefix       = errors.New("invalid length prefix")
+	ErrNotPatchable        = errors.New("writer does not support reservations")
+	ErrReservationSize     = errors.New("value does not fit reserved size")
+	ErrNoReservation       = errors.New("no open reservation at location")
+	ErrClosed              = errors.New("memory is closed")
+	ErrDiscarded           = errors.New("location has been discarded")
+	ErrCorruptFrame        = errors.New("corrupt frame")
+	ErrInvalidTag          = gobuftag.ErrInvalid
+	ErrUnsupportedType     = errors.New("unsupported type")
+	ErrOutOfRange          = errors.New("value out of range")
+	ErrInvalidBitCount     = errors.New("number of bits must be within [0, 64]")
+	ErrInvalidSeek         = errors.New("seek position out of range")
+	ErrNegativeOffset      = errors.New("negative offset")
+	ErrWriterClosed        = errors.New("writer is closed")
+	ErrUnfilledReservation = errors.New("reservation has not been filled")
+	ErrNoMark              = errors.New("no mark has been set")
+	ErrNotBuffered         = errors.New("writer is not buffered")
+	ErrQueueEmpty          = errors.New("queue is empty")
+	// ErrShortBuffer data ends in the middle of a value, an Error wrapping it also matches io.ErrUnexpectedEOF
+	ErrShortBuffer = errors.New("not enough bytes to decode")
+	ErrReadOnly    = errors.New("buffer is read-only")
 )
+
+// Error error of an operation on a buffer or memory, wraps one of the sentinel errors above or io.EOF
+type Error struct {
+	// Op operation failed, e.g. ReadUint32
+	Op string
+	// Offset reader or writer index where operation starts, location for memories
+	Offset int
+	// Want number of bytes operation needs
+	Want int
+	// Available number of bytes there were
+	Available int
+	Err       error
+}
+
+func (e *Error) Error() string {
+	if e.Want == 0 && e.Available == 0 {
+		return fmt.Sprintf("gobuf: %s at offset %d: %v", e.Op, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("gobuf: %s at offset %d: want %d bytes, %d available: %v", e.Op, e.Offset, e.Want, e.Available, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is short buffer errors match io.ErrUnexpectedEOF, which was returned before ErrShortBuffer
+func (e *Error) Is(target error) bool {
+	return target == io.ErrUnexpectedEOF && e.Err == ErrShortBuffer
+}
+
+// newError create an Error. an Error from a lower layer is unwrapped so errors are never nested,
+// its Available is kept as that layer knows best how many bytes there were
+func newError(op string, offset, want, available int, err error) error {
+	if e, ok := err.(*Error); ok {
+		err = e.Err
+		available = e.Available
+	}
+	return &Error{Op: op, Offset: offset, Want: want, Available: available, Err: err}
+}
+
+// withOp name operation of an Error after the method calling the one which failed, other errors are returned as is
+func withOp(err error, op string) error {
+	if e, ok := err.(*Error); ok {
+		e.Op = op
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Error", func() {
+	It("should report operation, offset and sizes", func() {
+		b := New([]byte{1, 2, 3, 4, 5, 6})
+		_, err := b.ReadUint32()
+		Expect(err).To(BeNil())
+
+		_, err = b.ReadUint32()
+		Expect(err).To(Equal(&Error{Op: "ReadUint32", Offset: 4, Want: 4, Available: 2, Err: ErrShortBuffer}))
+		Expect(err.Error()).To(Equal("gobuf: ReadUint32 at offset 4: want 4 bytes, 2 available: not enough bytes to decode"))
+		Expect(errors.Is(err, ErrShortBuffer)).To(BeTrue())
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+		Expect(errors.Is(err, io.EOF)).To(BeFalse())
+		Expect(b.ReaderIndex()).To(Equal(4))
+
+		_, err = b.PeekUint16(2)
+		Expect(err).To(Equal(&Error{Op: "PeekUint16", Offset: 6, Want: 2, Available: 0, Err: io.EOF}))
+		Expect(errors.Is(err, io.EOF)).To(BeTrue())
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeFalse())
+	})
+
+	It("should name the method called", func() {
+		b := New([]byte{0x80, 0x80})
+		_, err := b.ReadFloat64()
+		Expect(err.(*Error).Op).To(Equal("ReadFloat64"))
+		_, err = b.ReadBool()
+		Expect(err).To(BeNil())
+		_, err = b.ReadUvarint()
+		Expect(err).To(Equal(&Error{Op: "ReadUvarint", Offset: 1, Want: 2, Available: 1, Err: ErrShortBuffer}))
+		_, err = b.ReadLengthPrefixedString(PrefixUint16, 16)
+		Expect(err.(*Error).Op).To(Equal("ReadLengthPrefixedString"))
+		_, err = b.PeekBytes(-1)
+		Expect(err).To(Equal(&Error{Op: "PeekBytes", Offset: 1, Want: -1, Err: ErrOutOfRange}))
+
+		b = New([]byte{0, 100, 'a'}, WithBigEndian())
+		_, err = b.ReadLengthPrefixed(PrefixUint16, 10)
+		Expect(err).To(Equal(&Error{Op: "ReadLengthPrefixed", Offset: 2, Want: 100, Available: 10, Err: ErrTooLarge}))
+	})
+
+	It("should keep io.EOF of io interfaces bare", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(4)))
+		_, err := b.ReadByte()
+		Expect(err).To(Equal(io.EOF))
+		_, err = b.Read(make([]byte, 4))
+		Expect(err).To(Equal(io.EOF))
+		_, err = b.ReadAt(make([]byte, 4), 0)
+		Expect(err).To(Equal(io.EOF))
+		_, err = b.ReadAt(make([]byte, 4), -1)
+		Expect(err).To(Equal(&Error{Op: "ReadAt", Offset: -1, Want: 4, Err: ErrNegativeOffset}))
+	})
+
+	It("should report errors of memories", func() {
+		for _, m := range []Memory{NewSliceMemory(make([]byte, 8), nil), NewListMemory(make([]byte, 8), nil)} {
+			Expect(m.Write(-2, []byte{1})).To(Equal(&Error{Op: "Write", Offset: -2, Want: 1, Err: ErrNegativeOffset}))
+			Expect(m.Write(6, []byte{1, 2, 3})).To(Equal(&Error{Op: "Write", Offset: 6, Want: 3, Available: 2, Err: ErrOutOfSpace}))
+			Expect(m.Read(-1, make([]byte, 2))).To(Equal(&Error{Op: "Read", Offset: -1, Want: 2, Err: ErrNegativeOffset}))
+			Expect(m.Read(5, make([]byte, 4))).To(Equal(&Error{Op: "Read", Offset: 5, Want: 4, Available: 3, Err: io.EOF}))
+		}
+
+		b := New(make([]byte, 0, 3))
+		Expect(b.WriteUint16(1)).To(BeNil())
+		Expect(b.WriteUint32(1)).To(Equal(&Error{Op: "WriteUint32", Offset: 2, Want: 4, Available: 1, Err: ErrOutOfSpace}))
+		Expect(b.WriterIndex()).To(Equal(2))
+	})
+
+	It("should report errors of IOReader and IOWriter", func() {
+		r := Read(bytes.NewReader(make([]byte, 16)), binary.BigEndian, NewListMemory(nil, FixedGrow(4)), WithReadAhead(4))
+		_, err := r.ReadBytes(12)
+		Expect(err).To(BeNil())
+		_, err = r.ReadUint64()
+		Expect(err).To(Equal(&Error{Op: "ReadUint64", Offset: 12, Want: 8, Available: 4, Err: ErrShortBuffer}))
+		// read bytes are released while reading ahead
+		_, err = r.PeekUint32(-12)
+		Expect(err).To(Equal(&Error{Op: "PeekUint32", Offset: 0, Want: 4, Err: ErrDiscarded}))
+
+		var out bytes.Buffer
+		w := Write(&out, binary.BigEndian)
+		Expect(w.WriteUint16(1)).To(BeNil())
+		Expect(w.Close()).To(BeNil())
+		Expect(w.WriteUint32(1)).To(Equal(&Error{Op: "WriteUint32", Offset: 2, Want: 4, Err: ErrWriterClosed}))
+		Expect(w.Flush()).To(MatchError(ErrWriterClosed))
+	})
+
+	It("should reject writes to read-only buffers", func() {
+		data := []byte("hello world")
+		b := New(data, WithReadOnly())
+		s, err := b.ReadString(5)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("hello"))
+
+		Expect(b.WriteByte('!')).To(Equal(&Error{Op: "WriteByte", Offset: 0, Want: 1, Err: ErrReadOnly}))
+		_, err = b.WriteAt([]byte("x"), 0)
+		Expect(err).To(MatchError(ErrReadOnly))
+		_, err = b.Reserve(2)
+		Expect(err).To(MatchError(ErrReadOnly))
+		Expect(b.DiscardReadBytes()).To(MatchError(ErrReadOnly))
+
+		v, err := b.ReadSlice(1)
+		Expect(err).To(BeNil())
+		Expect(v.WriteString("x")).To(MatchError(ErrReadOnly))
+		Expect(string(data)).To(Equal("hello world"))
+	})
+})
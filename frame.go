@@ -0,0 +1,136 @@
+package gobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// DefaultMaxFrameLength max frame length used when FrameConfig.MaxFrameLength is not set
+const DefaultMaxFrameLength = 1 << 20
+
+// FrameConfig layout of frames with a length field in header, same as netty's LengthFieldBasedFrameDecoder
+type FrameConfig struct {
+	// LengthFieldOffset offset of length field from the beginning of frame
+	LengthFieldOffset int
+
+	// LengthFieldLength width of length field, one of 1, 2, 3, 4 and 8
+	LengthFieldLength int
+
+	// Order byte order of length field, order of the reader is used if nil
+	Order binary.ByteOrder
+
+	// LengthAdjustment added to value of length field to get number of bytes after length field
+	LengthAdjustment int
+
+	// InitialBytesToStrip number of bytes stripped from the beginning of decoded frame
+	InitialBytesToStrip int
+
+	// MaxFrameLength max length of a frame including header, DefaultMaxFrameLength if not set
+	MaxFrameLength int
+}
+
+// FrameDecoder decode length field based frames from a Reader
+type FrameDecoder struct {
+	reader *Reader
+	config FrameConfig
+}
+
+// NewFrameDecoder create a decoder reading frames from r, e.g. IOReader.Reader or Buffer.Reader
+func NewFrameDecoder(r *Reader, config FrameConfig) *FrameDecoder {
+	if config.Order == nil {
+		config.Order = r.Order()
+	}
+	if config.MaxFrameLength <= 0 {
+		config.MaxFrameLength = DefaultMaxFrameLength
+	}
+
+	return &FrameDecoder{
+		reader: r,
+		config: config,
+	}
+}
+
+// Decode decode next frame, stripped bytes are not included.
+// header is peeked without consuming, so nothing is read unless a complete frame is available:
+// io.EOF is returned if there is no more data and io.ErrUnexpectedEOF if frame is incomplete,
+// a Buffer being filled can be decoded again after more data is written
+func (d *FrameDecoder) Decode() (*Buffer, error) {
+	c := d.config
+
+	length, err := d.peekLength()
+	if err != nil {
+		return nil, err
+	}
+
+	index := d.reader.ReaderIndex()
+	headerEnd := c.LengthFieldOffset + c.LengthFieldLength
+	// frame length has to fit an int before it can be checked against MaxFrameLength
+	if length > uint64(maxInt-headerEnd) || (c.LengthAdjustment > 0 && int(length) > maxInt-headerEnd-c.LengthAdjustment) {
+		want := maxInt
+		if length < uint64(maxInt) {
+			want = int(length)
+		}
+		return nil, newError("Decode", index, want, c.MaxFrameLength, ErrTooLarge)
+	}
+
+	frameLength := int(length) + c.LengthAdjustment + headerEnd
+	if frameLength < headerEnd {
+		return nil, newError("Decode", index, 0, 0, ErrCorruptFrame)
+	}
+	if frameLength > c.MaxFrameLength {
+		return nil, newError("Decode", index, frameLength, c.MaxFrameLength, ErrTooLarge)
+	}
+	if c.InitialBytesToStrip > frameLength {
+		return nil, newError("Decode", index, 0, 0, ErrCorruptFrame)
+	}
+
+	b, err := d.reader.PeekBytes(frameLength-c.InitialBytesToStrip, c.InitialBytesToStrip)
+	if err != nil {
+		return nil, withOp(err, "Decode")
+	}
+	d.reader.SkipRead(frameLength)
+
+	frame := New(b)
+	frame.order = d.reader.Order()
+	return frame, nil
+}
+
+// peekLength peek value of length field
+func (d *FrameDecoder) peekLength() (uint64, error) {
+	c := d.config
+
+	var b [8]byte
+	width := c.LengthFieldLength
+	switch width {
+	case 1, 2, 3, 4, 8:
+	default:
+		return 0, ErrInvalidPrefix
+	}
+
+	if err := d.reader.peekFull("Decode", b[:width], []int{c.LengthFieldOffset}); err != nil {
+		// bytes before length field are available, frame is incomplete rather than absent
+		if errors.Is(err, io.EOF) && c.LengthFieldOffset > 0 {
+			if _, peekErr := d.reader.PeekByte(); peekErr == nil {
+				return 0, newError("Decode", d.reader.ReaderIndex(), c.LengthFieldOffset+width, d.reader.Available(), ErrShortBuffer)
+			}
+		}
+		return 0, err
+	}
+
+	switch width {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(c.Order.Uint16(b[:2])), nil
+	case 3:
+		if c.Order == binary.BigEndian {
+			return uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2]), nil
+		}
+		return uint64(b[2])<<16 | uint64(b[1])<<8 | uint64(b[0]), nil
+	case 4:
+		return uint64(c.Order.Uint32(b[:4])), nil
+	default:
+		return c.Order.Uint64(b[:8]), nil
+	}
+}
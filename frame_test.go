@@ -0,0 +1,145 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FrameDecoder", func() {
+	frame := func(d *FrameDecoder) string {
+		f, err := d.Decode()
+		Expect(err).To(BeNil())
+		s, err := f.ReadString(f.Available())
+		Expect(err).To(BeNil())
+		return s
+	}
+
+	It("should decode frame with length header", func() {
+		b := New(append([]byte{0x00, 0x05}, "hello"...), WithBigEndian())
+		d := NewFrameDecoder(b.Reader, FrameConfig{LengthFieldLength: 2})
+		Expect(frame(d)).To(Equal("\x00\x05hello"))
+		Expect(b.Available()).To(Equal(0))
+
+		_, err := d.Decode()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("should strip header", func() {
+		b := New(append([]byte{0x05, 0x00}, "hello"...))
+		d := NewFrameDecoder(b.Reader, FrameConfig{LengthFieldLength: 2, InitialBytesToStrip: 2})
+		Expect(frame(d)).To(Equal("hello"))
+	})
+
+	It("should adjust length including header", func() {
+		b := New(append([]byte{0x00, 0x07}, "hello"...))
+		d := NewFrameDecoder(b.Reader, FrameConfig{
+			LengthFieldLength:   2,
+			Order:               binary.BigEndian,
+			LengthAdjustment:    -2,
+			InitialBytesToStrip: 2,
+		})
+		Expect(frame(d)).To(Equal("hello"))
+	})
+
+	It("should decode length field after header", func() {
+		b := New(append([]byte{0xca, 0xfe, 0x00, 0x00, 0x05, 0x01}, "hello"...))
+		d := NewFrameDecoder(b.Reader, FrameConfig{
+			LengthFieldOffset:   2,
+			LengthFieldLength:   3,
+			Order:               binary.BigEndian,
+			LengthAdjustment:    1,
+			InitialBytesToStrip: 5,
+		})
+		Expect(frame(d)).To(Equal("\x01hello"))
+	})
+
+	It("should wait for incomplete frame", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)), WithBigEndian())
+		d := NewFrameDecoder(b.Reader, FrameConfig{LengthFieldOffset: 1, LengthFieldLength: 4, InitialBytesToStrip: 5})
+
+		_, err := d.Decode()
+		Expect(err).To(MatchError(io.EOF))
+
+		Expect(b.WriteUint8(1)).To(BeNil())
+		_, err = d.Decode()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+		Expect(b.WriteUint16(0)).To(BeNil())
+		_, err = d.Decode()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+		Expect(b.WriteUint16(5)).To(BeNil())
+		Expect(b.WriteString("hel")).To(BeNil())
+		_, err = d.Decode()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(b.ReaderIndex()).To(Equal(0))
+
+		Expect(b.WriteString("lo")).To(BeNil())
+		Expect(frame(d)).To(Equal("hello"))
+		Expect(b.ReaderIndex()).To(Equal(10))
+	})
+
+	It("should reject invalid frames", func() {
+		b := New([]byte{0xff, 0xff, 0x00})
+		d := NewFrameDecoder(b.Reader, FrameConfig{LengthFieldLength: 2, MaxFrameLength: 1024})
+		_, err := d.Decode()
+		Expect(err).To(MatchError(ErrTooLarge))
+
+		d = NewFrameDecoder(b.Reader, FrameConfig{LengthFieldLength: 1, LengthAdjustment: -512})
+		_, err = d.Decode()
+		Expect(err).To(MatchError(ErrCorruptFrame))
+
+		d = NewFrameDecoder(b.Reader, FrameConfig{LengthFieldLength: 5})
+		_, err = d.Decode()
+		Expect(err).To(Equal(ErrInvalidPrefix))
+		Expect(b.ReaderIndex()).To(Equal(0))
+
+		for length, want := range map[uint64]int{1 << 40: 1<<40 + 8, math.MaxUint64: maxInt} {
+			h := New(nil, WithAutoGrowMemory(FixedGrow(8)), WithBigEndian())
+			Expect(h.WriteUint64(length)).To(BeNil())
+			d = NewFrameDecoder(h.Reader, FrameConfig{LengthFieldLength: 8, MaxFrameLength: 1024})
+			_, err = d.Decode()
+			Expect(err).To(Equal(&Error{Op: "Decode", Offset: 0, Want: want, Available: 1024, Err: ErrTooLarge}))
+		}
+	})
+
+	It("should allow frames longer than MaxInt32 up to MaxFrameLength", func() {
+		h := New(nil, WithAutoGrowMemory(FixedGrow(16)), WithBigEndian())
+		Expect(h.WriteUint64(1 << 33)).To(BeNil())
+		Expect(h.WriteString("partial")).To(BeNil())
+		d := NewFrameDecoder(h.Reader, FrameConfig{LengthFieldLength: 8, MaxFrameLength: 1 << 40})
+		_, err := d.Decode()
+		Expect(err).To(Equal(&Error{Op: "Decode", Offset: 0, Want: 1<<33 + 8, Available: 15, Err: ErrShortBuffer}))
+		Expect(h.ReaderIndex()).To(Equal(0))
+
+		d = NewFrameDecoder(h.Reader, FrameConfig{LengthFieldLength: 8, LengthAdjustment: maxInt, MaxFrameLength: maxInt})
+		_, err = d.Decode()
+		Expect(err).To(MatchError(ErrTooLarge))
+	})
+
+	It("should decode frames from io reader", func() {
+		out := bytes.NewBuffer(nil)
+		w := Write(out, binary.BigEndian)
+		Expect(w.WriteLengthPrefixedString(PrefixUint32, "hello")).To(BeNil())
+		Expect(w.WriteLengthPrefixedString(PrefixUint32, "world")).To(BeNil())
+
+		r := Read(out, binary.BigEndian, NewSliceMemory(nil, FixedGrow(8)))
+		d := NewFrameDecoder(r.Reader, FrameConfig{LengthFieldLength: 4, InitialBytesToStrip: 4})
+		Expect(frame(d)).To(Equal("hello"))
+
+		f, err := d.Decode()
+		Expect(err).To(BeNil())
+		Expect(f.Order()).To(Equal(binary.BigEndian))
+		s, err := f.ReadString(5)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("world"))
+
+		_, err = d.Decode()
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
@@ -0,0 +1,342 @@
+//go:build go1.18
+// +build go1.18
+
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// fuzzReader a Reader under test, decoding same bytes must give same results whichever memory holds them
+type fuzzReader struct {
+	name   string
+	reader *Reader
+}
+
+func fuzzReaders(data []byte, order binary.ByteOrder) []fuzzReader {
+	orderOption := WithLittleEndian()
+	if order == binary.BigEndian {
+		orderOption = WithBigEndian()
+	}
+
+	slice := New(append([]byte(nil), data...), orderOption)
+	list := New(nil, WithLinkedListMemory(FixedGrow(3)), orderOption)
+	if err := list.WriteBytes(data); err != nil {
+		panic(err)
+	}
+	stream := Read(iotest.OneByteReader(bytes.NewReader(data)), order, NewListMemory(nil, FixedGrow(4)), WithReadAhead(4))
+	// keep every byte of stream, so reads at any location can be compared with buffers
+	stream.MarkReader()
+
+	return []fuzzReader{
+		{"SliceMemory", slice.Reader},
+		{"ListMemory", list.Reader},
+		{"IOReader", stream.Reader},
+	}
+}
+
+// fuzzOpSize size of an operation of FuzzReader, an op byte followed by a big endian int32 argument
+const fuzzOpSize = 5
+
+// fuzzOps encode pairs of op and argument as operations of FuzzReader
+func fuzzOps(pairs ...int) []byte {
+	var ops []byte
+	for i := 0; i+1 < len(pairs); i += 2 {
+		var arg [4]byte
+		binary.BigEndian.PutUint32(arg[:], uint32(int32(pairs[i+1])))
+		ops = append(append(ops, byte(pairs[i])), arg[:]...)
+	}
+	return ops
+}
+
+// fuzzResult result of an operation, errors are only compared by presence as each reader may fail differently
+func fuzzResult(v interface{}, err error) string {
+	if err != nil {
+		return "error"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// fuzzRead apply operation to r, arg is used as offset or length and may be negative. size is length of data
+func fuzzRead(r *Reader, op byte, arg int, delims [][]byte, size int) string {
+	prefix := LengthPrefix(arg&7) % (PrefixUvarint + 1)
+	switch op % 36 {
+	case 0:
+		return fuzzResult(r.PeekBool(arg))
+	case 1:
+		return fuzzResult(r.PeekByte(arg))
+	case 2:
+		return fuzzResult(r.PeekBytes(arg))
+	case 3:
+		return fuzzResult(r.PeekString(arg, arg/2))
+	case 4:
+		return fuzzResult(r.PeekUint16(arg))
+	case 5:
+		return fuzzResult(r.PeekUint32(arg))
+	case 6:
+		return fuzzResult(r.PeekUint64(arg))
+	case 7:
+		return fuzzResult(r.PeekInt16(arg))
+	case 8:
+		return fuzzResult(r.PeekInt32(arg))
+	case 9:
+		return fuzzResult(r.PeekInt64(arg))
+	case 10:
+		return fuzzResult(r.PeekFloat32(arg))
+	case 11:
+		return fuzzResult(r.PeekFloat64(arg))
+	case 12:
+		v, n, err := r.PeekUvarint(arg)
+		return fuzzResult([2]interface{}{v, n}, err)
+	case 13:
+		v, n, err := r.PeekVarint(arg)
+		return fuzzResult([2]interface{}{v, n}, err)
+	case 14:
+		v, n, err := r.PeekULEB128(arg)
+		return fuzzResult([2]interface{}{v, n}, err)
+	case 15:
+		v, n, err := r.PeekSLEB128(arg)
+		return fuzzResult([2]interface{}{v, n}, err)
+	case 16:
+		v, n, err := r.PeekProtoVarint(arg)
+		return fuzzResult([2]interface{}{v, n}, err)
+	case 17:
+		v, n, err := r.PeekLengthPrefixed(prefix, 64, arg)
+		return fuzzResult([2]interface{}{v, n}, err)
+	case 18:
+		return fuzzResult(r.ReadBool())
+	case 19:
+		return fuzzResult(r.ReadByte())
+	case 20:
+		return fuzzResult(r.ReadBytes(arg))
+	case 21:
+		return fuzzResult(r.ReadString(arg))
+	case 22:
+		return fuzzResult(r.ReadUint16())
+	case 23:
+		return fuzzResult(r.ReadUint32())
+	case 24:
+		return fuzzResult(r.ReadUint64())
+	case 25:
+		return fuzzResult(r.ReadInt16())
+	case 26:
+		return fuzzResult(r.ReadInt32())
+	case 27:
+		return fuzzResult(r.ReadInt64())
+	case 28:
+		return fuzzResult(r.ReadFloat64())
+	case 29:
+		return fuzzResult(r.ReadUvarint())
+	case 30:
+		return fuzzResult(r.ReadVarint())
+	case 31:
+		return fuzzResult(r.ReadSLEB128())
+	case 32:
+		return fuzzResult(r.ReadLengthPrefixed(prefix, 64))
+	case 33:
+		out, ok, err := r.ReadUntil(delims...)
+		return fuzzResult([2]interface{}{out, ok}, err)
+	case 34:
+		b := make([]byte, arg&0x1f)
+		n, err := r.Read(b)
+		return fuzzResult(b[:n], err)
+	default:
+		// keep reader index within data, Available of IOReader only counts bytes read ahead
+		if arg > 0 {
+			r.SkipRead(arg % (size - r.ReaderIndex() + 1))
+		}
+		return fuzzResult(r.ReaderIndex(), nil)
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	f.Add([]byte("hello\r\nworld"), fuzzOps(33, 0, 20, 3, 5, -1), []byte("\r\n"), false)
+	f.Add([]byte{0x96, 0x01, 0x03, 'a', 'b', 'c'}, fuzzOps(12, 0, 29, 0, 32, 1, 2, -128), []byte{0}, true)
+	f.Add([]byte("abc"), fuzzOps(33, 0), []byte("c|xyz"), false)
+	f.Add([]byte("abc"), fuzzOps(20, 1<<30, 21, -1<<31, 2, 1<<31-1), []byte(""), false)
+
+	f.Fuzz(func(t *testing.T, data, ops, delimiters []byte, bigEndian bool) {
+		var order binary.ByteOrder = binary.LittleEndian
+		if bigEndian {
+			order = binary.BigEndian
+		}
+		delims := bytes.Split(delimiters, []byte("|"))
+
+		readers := fuzzReaders(data, order)
+		for i := 0; i+fuzzOpSize <= len(ops); i += fuzzOpSize {
+			// full width, so lengths and offsets far beyond data are tried
+			arg := int(int32(binary.BigEndian.Uint32(ops[i+1:])))
+			want := ""
+			for j, r := range readers {
+				got := fuzzRead(r.reader, ops[i], arg, delims, len(data))
+				if r.reader.ReaderIndex() < 0 || r.reader.ReaderIndex() > len(data) {
+					t.Fatalf("%s: op %d moved reader index to %d", r.name, ops[i], r.reader.ReaderIndex())
+				}
+				if j == 0 {
+					want = got
+				} else if got != want {
+					t.Fatalf("%s: op %d(%d) = %s, SliceMemory = %s", r.name, ops[i], arg, got, want)
+				}
+			}
+		}
+	})
+}
+
+func FuzzReadUntil(f *testing.F) {
+	f.Add([]byte("GET / HTTP/1.1\r\nHost: a\r\n\r\n"), []byte("\r\n"), []byte("\n"))
+	f.Add([]byte("ab"), []byte("b"), []byte("xyz"))
+
+	f.Fuzz(func(t *testing.T, data, delim1, delim2 []byte) {
+		for _, r := range fuzzReaders(data, binary.BigEndian) {
+			for r.reader.Available() > 0 {
+				index := r.reader.ReaderIndex()
+				out, ok, err := r.reader.ReadUntil(delim1, delim2)
+				if err != nil || !ok {
+					break
+				}
+				if !bytes.HasPrefix(data[index:], out) {
+					t.Fatalf("%s: ReadUntil = %q, not a prefix of %q", r.name, out, data[index:])
+				}
+				if r.reader.ReaderIndex() == index {
+					break
+				}
+			}
+		}
+	})
+}
+
+func FuzzMemory(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 10, 1, 0, 0, 5})
+	f.Add([]byte{0, 0xff, 0xff, 3, 1, 0xff, 0xff, 3})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		memories := []Memory{
+			NewSliceMemory(nil, FixedGrow(5)),
+			NewListMemory(nil, FixedGrow(5)),
+		}
+		for _, m := range memories {
+			// ref bytes written so far
+			var ref []byte
+			for i := 0; i+3 < len(ops); i += 4 {
+				at := int(int16(binary.BigEndian.Uint16(ops[i+1:])))
+				n := int(ops[i+3])
+
+				switch ops[i] % 5 {
+				case 0:
+					src := bytes.Repeat([]byte{ops[i+3] | 1}, n)
+					err := m.Write(at, src)
+					if at < 0 {
+						if err == nil {
+							t.Fatalf("%T: Write(%d) succeeded", m, at)
+						}
+						continue
+					}
+					if err != nil {
+						t.Fatalf("%T: Write(%d, %d bytes): %v", m, at, n, err)
+					}
+					if n == 0 {
+						continue
+					}
+					if at+n > len(ref) {
+						ref = append(ref, make([]byte, at+n-len(ref))...)
+					}
+					copy(ref[at:], src)
+				case 1:
+					dst := make([]byte, n)
+					err := m.Read(at, dst)
+					switch {
+					case at < 0:
+						if err == nil {
+							t.Fatalf("%T: Read(%d) succeeded", m, at)
+						}
+					case at+n <= len(ref):
+						if err != nil {
+							t.Fatalf("%T: Read(%d, %d bytes): %v", m, at, n, err)
+						}
+						if !bytes.Equal(dst, ref[at:at+n]) {
+							t.Fatalf("%T: Read(%d, %d bytes) = %x, want %x", m, at, n, dst, ref[at:at+n])
+						}
+					case at+n > m.Length():
+						if !errors.Is(err, io.EOF) {
+							t.Fatalf("%T: Read(%d, %d bytes) beyond length %d: %v", m, at, n, m.Length(), err)
+						}
+					}
+				case 2:
+					if n > len(ref) {
+						n = len(ref)
+					}
+					m.(Discardable).Discard(n)
+					ref = ref[n:]
+				case 3:
+					out := m.Bytes()
+					if len(out) < len(ref) || !bytes.Equal(out[:len(ref)], ref) {
+						t.Fatalf("%T: Bytes() = %x, want prefix %x", m, out, ref)
+					}
+				default:
+					m.Reset()
+					ref = nil
+				}
+			}
+		}
+	})
+}
+
+func FuzzIOReader(f *testing.F) {
+	f.Add([]byte("hello world"), []byte{3, 0, 5, 1, 2})
+	f.Add(bytes.Repeat([]byte("0123456789"), 10), []byte{1, 7, 0x80, 9, 1})
+
+	f.Fuzz(func(t *testing.T, data, ops []byte) {
+		readAhead := 1
+		if len(ops) > 0 {
+			readAhead += int(ops[0] & 0xf)
+		}
+		r := Read(iotest.HalfReader(bytes.NewReader(data)), binary.BigEndian, NewListMemory(nil, FixedGrow(readAhead)), WithReadAhead(readAhead))
+
+		marked := -1
+		for i := 1; i < len(ops); i++ {
+			index := r.ReaderIndex()
+			switch op := ops[i]; {
+			case op == 0x80:
+				r.MarkReader()
+				marked = index
+			case op == 0x81:
+				err := r.ResetToMark()
+				if (err == nil) != (marked >= 0) {
+					t.Fatalf("ResetToMark() = %v with mark at %d", err, marked)
+				}
+			case op&0x80 != 0:
+				// peek before reader index, fails once bytes are released unless mark keeps them
+				back := int(op & 0x7f)
+				b, err := r.PeekBytes(1, -back)
+				if err == nil && (index-back < 0 || b[0] != data[index-back]) {
+					t.Fatalf("PeekBytes(1, %d) at %d = %x", -back, index, b)
+				}
+				if err != nil && marked >= 0 && index-back >= marked && index-back < len(data) {
+					t.Fatalf("PeekBytes(1, %d) at %d after mark at %d: %v", -back, index, marked, err)
+				}
+			default:
+				b, err := r.ReadBytes(int(op))
+				if err == nil && !bytes.Equal(b, data[index:index+int(op)]) {
+					t.Fatalf("ReadBytes(%d) at %d = %x, want %x", op, index, b, data[index:index+int(op)])
+				}
+				if err == nil && r.ReaderIndex() != index+int(op) {
+					t.Fatalf("ReadBytes(%d) moved reader index from %d to %d", op, index, r.ReaderIndex())
+				}
+			}
+		}
+
+		rest, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if index := r.ReaderIndex(); index != len(data) || len(rest) > len(data) || !bytes.Equal(rest, data[len(data)-len(rest):]) {
+			t.Fatalf("ReadAll at end %d = %x, data %x", index, rest, data)
+		}
+	})
+}
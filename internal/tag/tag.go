@@ -0,0 +1,122 @@
+// Package tag parses `gobuf` struct tags, shared by reflection based marshalling and cmd/gobufgen
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMax max length of strings, bytes and slices when unmarshalling, unless limited by max=
+const DefaultMax = 1 << 20
+
+// DefaultLen length prefix of strings, bytes and slices, unless set by len=
+const DefaultLen = "uvarint"
+
+var ErrInvalid = errors.New("invalid gobuf tag")
+
+// Wires wire types of numeric fields
+var Wires = map[string]bool{
+	"bool":    true,
+	"u8":      true,
+	"u16":     true,
+	"u32":     true,
+	"u64":     true,
+	"i8":      true,
+	"i16":     true,
+	"i32":     true,
+	"i64":     true,
+	"f32":     true,
+	"f64":     true,
+	"uvarint": true,
+	"varint":  true,
+}
+
+// Lens length prefixes of strings, bytes and slices
+var Lens = map[string]bool{
+	"u8":      true,
+	"u16":     true,
+	"u32":     true,
+	"u64":     true,
+	"uvarint": true,
+}
+
+var defaultWires = map[string]string{
+	"bool":    "bool",
+	"int":     "varint",
+	"int8":    "i8",
+	"int16":   "i16",
+	"int32":   "i32",
+	"int64":   "i64",
+	"uint":    "uvarint",
+	"uint8":   "u8",
+	"uint16":  "u16",
+	"uint32":  "u32",
+	"uint64":  "u64",
+	"float32": "f32",
+	"float64": "f64",
+}
+
+// DefaultWire wire type of a basic go type (e.g. "uint16") when not given in tag, empty if it is not numeric
+func DefaultWire(typ string) string {
+	return defaultWires[typ]
+}
+
+// Tag parsed `gobuf:"..."` tag, e.g. `gobuf:"u16,be"`, `gobuf:"len=u8,max=64"` or `gobuf:"-"`
+type Tag struct {
+	// Skip field is ignored
+	Skip bool
+	// Wire wire type, empty if inferred from field type
+	Wire string
+	// Order "be", "le" or empty to follow order of buffer
+	Order string
+	// Len length prefix
+	Len string
+	// Max max length accepted when unmarshalling
+	Max int
+}
+
+// Parse parse content of a `gobuf` tag
+func Parse(s string) (Tag, error) {
+	t := Tag{
+		Len: DefaultLen,
+		Max: DefaultMax,
+	}
+	if s == "-" {
+		t.Skip = true
+		return t, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		key, value := item, ""
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			key, value = item[:i], item[i+1:]
+		}
+
+		switch key {
+		case "":
+		case "be", "le":
+			t.Order = key
+		case "len":
+			if !Lens[value] {
+				return t, fmt.Errorf("%w: unknown length prefix %q", ErrInvalid, value)
+			}
+			t.Len = value
+		case "max":
+			max, err := strconv.Atoi(value)
+			if err != nil || max < 0 {
+				return t, fmt.Errorf("%w: invalid max %q", ErrInvalid, value)
+			}
+			t.Max = max
+		default:
+			if !Wires[key] || value != "" {
+				return t, fmt.Errorf("%w: unknown option %q", ErrInvalid, item)
+			}
+			t.Wire = key
+		}
+	}
+
+	return t, nil
+}
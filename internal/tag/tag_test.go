@@ -0,0 +1,47 @@
+package tag
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "tag")
+}
+
+var _ = Describe("Tag", func() {
+	It("should parse", func() {
+		tests := []struct {
+			tag      string
+			expected Tag
+		}{
+			{"", Tag{Len: DefaultLen, Max: DefaultMax}},
+			{"-", Tag{Skip: true, Len: DefaultLen, Max: DefaultMax}},
+			{"u16,be", Tag{Wire: "u16", Order: "be", Len: DefaultLen, Max: DefaultMax}},
+			{"len=u8, max=64", Tag{Len: "u8", Max: 64}},
+			{"varint,le", Tag{Wire: "varint", Order: "le", Len: DefaultLen, Max: DefaultMax}},
+		}
+
+		for _, test := range tests {
+			tag, err := Parse(test.tag)
+			Expect(err).To(BeNil())
+			Expect(tag).To(Equal(test.expected))
+		}
+	})
+
+	It("should reject invalid tag", func() {
+		for _, invalid := range []string{"u17", "len=u7", "max=-1", "max=a", "u8=1"} {
+			_, err := Parse(invalid)
+			Expect(err).To(MatchError(ContainSubstring(ErrInvalid.Error())))
+		}
+	})
+
+	It("should infer wire type", func() {
+		Expect(DefaultWire("uint16")).To(Equal("u16"))
+		Expect(DefaultWire("int")).To(Equal("varint"))
+		Expect(DefaultWire("string")).To(Equal(""))
+	})
+})
@@ -0,0 +1,111 @@
+package gobuf
+
+// writerMarkable a Writable which needs to keep state from MarkWriter to ResetWriterToMark
+type writerMarkable interface {
+	markWriter(at int) error
+	resetWriter(at int) error
+}
+
+// MarkReader save reader index to be restored by ResetToMark, bytes from the mark on are retained until UnmarkReader
+func (p *Peeker) MarkReader() {
+	p.mark = p.index
+	p.marked = true
+}
+
+// ResetToMark restore reader index saved by MarkReader, mark is kept
+func (p *Peeker) ResetToMark() error {
+	if !p.marked {
+		return newError("ResetToMark", p.index, 0, 0, ErrNoMark)
+	}
+	p.index = p.mark
+	return nil
+}
+
+// UnmarkReader drop mark saved by MarkReader
+func (p *Peeker) UnmarkReader() {
+	p.marked = false
+}
+
+// MarkWriter save writer index to be restored by ResetWriterToMark
+func (w *Writer) MarkWriter() error {
+	if m, ok := w.Writable.(writerMarkable); ok {
+		if err := m.markWriter(w.index); err != nil {
+			return err
+		}
+	}
+
+	w.mark = w.index
+	w.marked = true
+	return nil
+}
+
+// ResetWriterToMark restore writer index saved by MarkWriter and drop what is written since, mark is kept
+func (w *Writer) ResetWriterToMark() error {
+	if !w.marked {
+		return newError("ResetWriterToMark", w.index, 0, 0, ErrNoMark)
+	}
+
+	if m, ok := w.Writable.(writerMarkable); ok {
+		if err := m.resetWriter(w.mark); err != nil {
+			return err
+		}
+	}
+
+	w.index = w.mark
+	return nil
+}
+
+// UnmarkWriter drop mark saved by MarkWriter
+func (w *Writer) UnmarkWriter() {
+	w.marked = false
+}
+
+func (buf *Buffer) markWriter(at int) error {
+	buf.markSize = buf.size
+	return nil
+}
+
+func (buf *Buffer) resetWriter(at int) error {
+	buf.size = buf.markSize
+	return nil
+}
+
+// Tx run fn, both indexes and Size are restored if it returns an error.
+// read bytes are not discarded automatically while fn runs, and fn must not call DiscardReadBytes
+func (buf *Buffer) Tx(fn func(*Buffer) error) error {
+	readerIndex, writerIndex, size := buf.ReaderIndex(), buf.WriterIndex(), buf.size
+
+	autoDiscard := buf.autoDiscard
+	buf.autoDiscard = 0
+	defer func() {
+		buf.autoDiscard = autoDiscard
+	}()
+
+	if err := fn(buf); err != nil {
+		buf.Peeker.index = readerIndex
+		buf.Writer.index = writerIndex
+		buf.size = size
+		return err
+	}
+	return nil
+}
+
+func (w *IOWriter) markWriter(at int) error {
+	if !w.buffered {
+		return newError("MarkWriter", at, 0, 0, ErrNotBuffered)
+	}
+	if w.held == 0 {
+		w.base = at
+	}
+	return nil
+}
+
+func (w *IOWriter) resetWriter(at int) error {
+	w.held = at - w.base
+	for key := range w.reservations {
+		if key >= at {
+			delete(w.reservations, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mark", func() {
+	It("should reset reader to mark", func() {
+		b := New([]byte{0, 1, 0, 2, 0, 3})
+		Expect(b.ResetToMark()).To(MatchError(ErrNoMark))
+
+		_, _ = b.ReadUint16()
+		b.MarkReader()
+		_, _ = b.ReadUint16()
+		_, _ = b.ReadUint16()
+		Expect(b.ResetToMark()).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(2))
+		_, _ = b.ReadUint8()
+		Expect(b.ResetToMark()).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(2))
+
+		b.UnmarkReader()
+		Expect(b.ResetToMark()).To(MatchError(ErrNoMark))
+	})
+
+	It("should reset writer and size to mark", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)))
+		Expect(b.ResetWriterToMark()).To(MatchError(ErrNoMark))
+
+		Expect(b.WriteString("head")).To(BeNil())
+		Expect(b.MarkWriter()).To(BeNil())
+		Expect(b.WriteString("half written")).To(BeNil())
+		Expect(b.ResetWriterToMark()).To(BeNil())
+		Expect(b.WriterIndex()).To(Equal(4))
+		Expect(b.Size()).To(Equal(4))
+
+		Expect(b.WriteString("tail")).To(BeNil())
+		s, err := b.ReadString(b.Available())
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("headtail"))
+	})
+
+	It("should keep marked bytes when discarding", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)), WithAutoDiscard(4))
+		Expect(b.WriteString("0123456789")).To(BeNil())
+		b.SkipRead(2)
+		b.MarkReader()
+		b.SkipRead(6)
+		Expect(b.WriteString("ab")).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(6))
+
+		Expect(b.ResetToMark()).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(0))
+		s, err := b.ReadString(b.Available())
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("23456789ab"))
+	})
+
+	It("should roll back transaction on error", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)), WithAutoDiscard(1))
+		Expect(b.WriteString("abcd")).To(BeNil())
+
+		failure := errors.New("failure")
+		err := b.Tx(func(b *Buffer) error {
+			_, _ = b.ReadUint16()
+			Expect(b.WriteString("half")).To(BeNil())
+			return failure
+		})
+		Expect(err).To(Equal(failure))
+		Expect(b.ReaderIndex()).To(Equal(0))
+		Expect(b.WriterIndex()).To(Equal(4))
+		Expect(b.Size()).To(Equal(4))
+
+		Expect(b.Tx(func(b *Buffer) error {
+			_, _ = b.ReadUint16()
+			return b.WriteString("ef")
+		})).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(2))
+		Expect(b.Size()).To(Equal(6))
+	})
+
+	It("should retain bytes after mark in IOReader", func() {
+		data := make([]byte, 1000)
+		for i := range data {
+			data[i] = byte(i)
+		}
+
+		r := Read(iotest.OneByteReader(bytes.NewReader(data)), binary.BigEndian, NewSliceMemory(nil, FixedGrow(16)), WithReadAhead(16))
+		_, _ = r.ReadBytes(10)
+		r.MarkReader()
+		_, err := r.ReadBytes(500)
+		Expect(err).To(BeNil())
+		Expect(r.ResetToMark()).To(BeNil())
+
+		b, err := r.ReadBytes(990)
+		Expect(err).To(BeNil())
+		Expect(b).To(Equal(data[10:]))
+		_, err = r.ReadUint8()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("should hold back buffered IOWriter after mark", func() {
+		out := bytes.NewBuffer(nil)
+		w := Write(out, binary.BigEndian, WithWriteBuffer(nil, 4))
+
+		Expect(w.WriteString("ab")).To(BeNil())
+		Expect(w.MarkWriter()).To(BeNil())
+		Expect(w.WriteString("half written")).To(BeNil())
+		Expect(out.String()).To(Equal("ab"))
+
+		Expect(w.ResetWriterToMark()).To(BeNil())
+		Expect(w.WriterIndex()).To(Equal(2))
+		Expect(w.Buffered()).To(Equal(0))
+		Expect(w.WriteString("record")).To(BeNil())
+		w.UnmarkWriter()
+		Expect(w.Flush()).To(BeNil())
+		Expect(out.String()).To(Equal("abrecord"))
+
+		Expect(Write(&strings.Builder{}, binary.BigEndian).MarkWriter()).To(MatchError(ErrNotBuffered))
+	})
+})
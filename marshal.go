@@ -0,0 +1,637 @@
+package gobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	gobuftag "github.com/joesonw/gobuf/internal/tag"
+)
+
+// DefaultMaxLength max length of strings, bytes and slices when unmarshalling, unless limited by max= in tag
+const DefaultMaxLength = gobuftag.DefaultMax
+
+// FieldError error of marshalling or unmarshalling a field
+type FieldError struct {
+	// Path path to field from marshalled struct, e.g. Header.Items[2].Name
+	Path string
+	// Offset writer or reader index where field starts
+	Offset int
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("gobuf: field %s at offset %d: %v", e.Path, e.Offset, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// wireType encoding of a numeric field
+type wireType int
+
+const (
+	wireBool wireType = iota + 1
+	wireU8
+	wireU16
+	wireU32
+	wireU64
+	wireI8
+	wireI16
+	wireI32
+	wireI64
+	wireF32
+	wireF64
+	wireUvarint
+	wireVarint
+)
+
+var wireTypes = map[string]wireType{
+	"bool":    wireBool,
+	"u8":      wireU8,
+	"u16":     wireU16,
+	"u32":     wireU32,
+	"u64":     wireU64,
+	"i8":      wireI8,
+	"i16":     wireI16,
+	"i32":     wireI32,
+	"i64":     wireI64,
+	"f32":     wireF32,
+	"f64":     wireF64,
+	"uvarint": wireUvarint,
+	"varint":  wireVarint,
+}
+
+var lengthPrefixes = map[string]LengthPrefix{
+	"u8":      PrefixUint8,
+	"u16":     PrefixUint16,
+	"u32":     PrefixUint32,
+	"u64":     PrefixUint64,
+	"uvarint": PrefixUvarint,
+}
+
+// fieldTag parsed `gobuf` tag resolved to wire types and prefixes
+type fieldTag struct {
+	skip   bool
+	wire   wireType
+	order  binary.ByteOrder
+	prefix LengthPrefix
+	max    int
+}
+
+func parseTag(s string) (fieldTag, error) {
+	parsed, err := gobuftag.Parse(s)
+	if err != nil {
+		return fieldTag{}, err
+	}
+
+	t := fieldTag{
+		skip:   parsed.Skip,
+		wire:   wireTypes[parsed.Wire],
+		prefix: lengthPrefixes[parsed.Len],
+		max:    parsed.Max,
+	}
+	switch parsed.Order {
+	case "be":
+		t.order = binary.BigEndian
+	case "le":
+		t.order = binary.LittleEndian
+	}
+	return t, nil
+}
+
+// codec encode and decode values of a type
+type codec interface {
+	encode(w *Writer, v reflect.Value) error
+	decode(r *Reader, v reflect.Value) error
+}
+
+// Marshaler type which can write itself, e.g. methods generated by cmd/gobufgen
+type Marshaler interface {
+	MarshalGobuf(w *Writer) error
+}
+
+// Unmarshaler type which can read itself, e.g. methods generated by cmd/gobufgen
+type Unmarshaler interface {
+	UnmarshalGobuf(r *Reader) error
+}
+
+// Marshal write fields of struct v into w, as described by `gobuf` tags.
+// MarshalGobuf is used instead if v implements Marshaler
+func Marshal(w *Writer, v interface{}) error {
+	if m, ok := v.(Marshaler); ok {
+		return m.MarshalGobuf(w)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("gobuf: cannot marshal %T: %w", v, ErrUnsupportedType)
+	}
+
+	c, err := structCodecOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	return c.encode(w, rv)
+}
+
+// Unmarshal read fields of struct pointed by v from r, as described by `gobuf` tags.
+// UnmarshalGobuf is used instead if v implements Unmarshaler
+func Unmarshal(r *Reader, v interface{}) error {
+	if u, ok := v.(Unmarshaler); ok {
+		return u.UnmarshalGobuf(r)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("gobuf: cannot unmarshal into %T: %w", v, ErrUnsupportedType)
+	}
+
+	c, err := structCodecOf(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	return c.decode(r, rv.Elem())
+}
+
+type structField struct {
+	index int
+	name  string
+	codec codec
+}
+
+type structCodec struct {
+	fields []structField
+}
+
+var structCodecs sync.Map
+
+func structCodecOf(t reflect.Type) (*structCodec, error) {
+	if c, ok := structCodecs.Load(t); ok {
+		return c.(*structCodec), nil
+	}
+
+	c, err := newStructCodec(t, map[reflect.Type]*structCodec{})
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := structCodecs.LoadOrStore(t, c)
+	return actual.(*structCodec), nil
+}
+
+// newStructCodec build codec of struct t, building tracks codecs being built for recursive types
+func newStructCodec(t reflect.Type, building map[reflect.Type]*structCodec) (*structCodec, error) {
+	if c, ok := building[t]; ok {
+		return c, nil
+	}
+
+	c := &structCodec{}
+	building[t] = c
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag, err := parseTag(f.Tag.Get("gobuf"))
+		if err != nil {
+			return nil, fmt.Errorf("gobuf: field %s.%s: %w", t.Name(), f.Name, err)
+		}
+		if tag.skip {
+			continue
+		}
+
+		fc, err := newCodec(f.Type, tag, building)
+		if err != nil {
+			return nil, fmt.Errorf("gobuf: field %s.%s: %w", t.Name(), f.Name, err)
+		}
+		c.fields = append(c.fields, structField{
+			index: i,
+			name:  f.Name,
+			codec: fc,
+		})
+	}
+	return c, nil
+}
+
+func newCodec(t reflect.Type, tag fieldTag, building map[reflect.Type]*structCodec) (codec, error) {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return newNumberCodec(t.Kind(), tag)
+	case reflect.String:
+		if tag.wire != 0 {
+			return nil, fmt.Errorf("%w: wire type does not match %s", ErrInvalidTag, t.Kind())
+		}
+		return stringCodec{prefix: tag.prefix, max: tag.max}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && tag.wire == 0 {
+			return bytesCodec{prefix: tag.prefix, max: tag.max}, nil
+		}
+		elem, err := newCodec(t.Elem(), tag, building)
+		if err != nil {
+			return nil, err
+		}
+		return sliceCodec{prefix: tag.prefix, max: tag.max, elem: elem}, nil
+	case reflect.Array:
+		elem, err := newCodec(t.Elem(), tag, building)
+		if err != nil {
+			return nil, err
+		}
+		return arrayCodec{elem: elem}, nil
+	case reflect.Struct:
+		if tag.wire != 0 {
+			return nil, fmt.Errorf("%w: wire type does not match %s", ErrInvalidTag, t.Kind())
+		}
+		return newStructCodec(t, building)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+}
+
+func (c *structCodec) encode(w *Writer, v reflect.Value) error {
+	for _, f := range c.fields {
+		offset := w.WriterIndex()
+		if err := f.codec.encode(w, v.Field(f.index)); err != nil {
+			return wrapFieldError(err, f.name, offset)
+		}
+	}
+	return nil
+}
+
+func (c *structCodec) decode(r *Reader, v reflect.Value) error {
+	for _, f := range c.fields {
+		offset := r.ReaderIndex()
+		if err := f.codec.decode(r, v.Field(f.index)); err != nil {
+			return wrapFieldError(err, f.name, offset)
+		}
+	}
+	return nil
+}
+
+// wrapFieldError prefix path of a FieldError with name, or create one at offset
+func wrapFieldError(err error, name string, offset int) error {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		return &FieldError{Path: name, Offset: offset, Err: err}
+	}
+
+	if strings.HasPrefix(fe.Path, "[") {
+		fe.Path = name + fe.Path
+	} else {
+		fe.Path = name + "." + fe.Path
+	}
+	return fe
+}
+
+type numberCodec struct {
+	wire  wireType
+	order binary.ByteOrder
+}
+
+// newNumberCodec validate wire type against kind, infer it if not given
+func newNumberCodec(kind reflect.Kind, tag fieldTag) (codec, error) {
+	wire := tag.wire
+	if wire == 0 {
+		wire = wireTypes[gobuftag.DefaultWire(kind.String())]
+	}
+
+	valid := false
+	switch wire {
+	case wireBool:
+		valid = kind == reflect.Bool
+	case wireF32, wireF64:
+		valid = kind == reflect.Float32 || kind == reflect.Float64
+	default:
+		valid = isInteger(kind)
+	}
+	if !valid {
+		return nil, fmt.Errorf("%w: wire type does not match %s", ErrInvalidTag, kind)
+	}
+
+	return numberCodec{wire: wire, order: tag.order}, nil
+}
+
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isSigned(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// bits size of fixed width wire type
+func (t wireType) bits() uint {
+	switch t {
+	case wireU8, wireI8:
+		return 8
+	case wireU16, wireI16:
+		return 16
+	case wireU32, wireI32, wireF32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+func (t wireType) signed() bool {
+	switch t {
+	case wireI8, wireI16, wireI32, wireI64, wireVarint:
+		return true
+	}
+	return false
+}
+
+// integer get integer value of v as bits of wire type, fails if it does not fit
+func (c numberCodec) integer(v reflect.Value) (uint64, error) {
+	bits := c.wire.bits()
+	if isSigned(v.Kind()) {
+		i := v.Int()
+		if c.wire.signed() {
+			if bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+				return 0, ErrOutOfRange
+			}
+			return uint64(i), nil
+		}
+		if i < 0 || (bits < 64 && uint64(i) >= 1<<bits) {
+			return 0, ErrOutOfRange
+		}
+		return uint64(i), nil
+	}
+
+	u := v.Uint()
+	if c.wire.signed() {
+		if u >= 1<<(bits-1) {
+			return 0, ErrOutOfRange
+		}
+		return u, nil
+	}
+	if bits < 64 && u >= 1<<bits {
+		return 0, ErrOutOfRange
+	}
+	return u, nil
+}
+
+// setInteger set decoded integer to v, fails if it does not fit
+func (c numberCodec) setInteger(v reflect.Value, u uint64) error {
+	if isSigned(v.Kind()) {
+		i := int64(u)
+		if !c.wire.signed() {
+			if i < 0 {
+				return ErrOutOfRange
+			}
+		} else if bits := c.wire.bits(); bits < 64 {
+			// sign extend
+			i = i << (64 - bits) >> (64 - bits)
+		}
+		if v.OverflowInt(i) {
+			return ErrOutOfRange
+		}
+		v.SetInt(i)
+		return nil
+	}
+
+	if c.wire.signed() {
+		if bits := c.wire.bits(); bits < 64 {
+			u = uint64(int64(u<<(64-bits)) >> (64 - bits))
+		}
+		if int64(u) < 0 {
+			return ErrOutOfRange
+		}
+	}
+	if v.OverflowUint(u) {
+		return ErrOutOfRange
+	}
+	v.SetUint(u)
+	return nil
+}
+
+func (c numberCodec) encode(w *Writer, v reflect.Value) error {
+	order := c.order
+	if order == nil {
+		order = w.Order()
+	}
+
+	switch c.wire {
+	case wireBool:
+		return w.WriteBool(v.Bool())
+	case wireF32:
+		order.PutUint32(w.scratch[:4], math.Float32bits(float32(v.Float())))
+		return w.WriteBytes(w.scratch[:4])
+	case wireF64:
+		order.PutUint64(w.scratch[:8], math.Float64bits(v.Float()))
+		return w.WriteBytes(w.scratch[:8])
+	}
+
+	u, err := c.integer(v)
+	if err != nil {
+		return err
+	}
+
+	switch c.wire {
+	case wireU8, wireI8:
+		return w.WriteUint8(uint8(u))
+	case wireU16, wireI16:
+		order.PutUint16(w.scratch[:2], uint16(u))
+		return w.WriteBytes(w.scratch[:2])
+	case wireU32, wireI32:
+		order.PutUint32(w.scratch[:4], uint32(u))
+		return w.WriteBytes(w.scratch[:4])
+	case wireUvarint:
+		return w.WriteUvarint(u)
+	case wireVarint:
+		return w.WriteVarint(int64(u))
+	default:
+		order.PutUint64(w.scratch[:8], u)
+		return w.WriteBytes(w.scratch[:8])
+	}
+}
+
+func (c numberCodec) decode(r *Reader, v reflect.Value) error {
+	order := c.order
+	if order == nil {
+		order = r.Order()
+	}
+
+	var u uint64
+	switch c.wire {
+	case wireBool:
+		b, err := r.ReadBool()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case wireU8, wireI8:
+		b, err := r.ReadUint8()
+		if err != nil {
+			return err
+		}
+		u = uint64(b)
+	case wireUvarint:
+		i, err := r.ReadUvarint()
+		if err != nil {
+			return err
+		}
+		u = i
+	case wireVarint:
+		i, err := r.ReadVarint()
+		if err != nil {
+			return err
+		}
+		u = uint64(i)
+	default:
+		size := int(c.wire.bits() / 8)
+		b, err := r.peekScratch("Unmarshal", size, nil)
+		if err != nil {
+			return err
+		}
+		r.SkipRead(size)
+
+		switch size {
+		case 2:
+			u = uint64(order.Uint16(b))
+		case 4:
+			u = uint64(order.Uint32(b))
+		default:
+			u = order.Uint64(b)
+		}
+	}
+
+	switch c.wire {
+	case wireF32:
+		v.SetFloat(float64(math.Float32frombits(uint32(u))))
+		return nil
+	case wireF64:
+		v.SetFloat(math.Float64frombits(u))
+		return nil
+	}
+	return c.setInteger(v, u)
+}
+
+type stringCodec struct {
+	prefix LengthPrefix
+	max    int
+}
+
+func (c stringCodec) encode(w *Writer, v reflect.Value) error {
+	return w.WriteLengthPrefixedString(c.prefix, v.String())
+}
+
+func (c stringCodec) decode(r *Reader, v reflect.Value) error {
+	s, err := r.ReadLengthPrefixedString(c.prefix, c.max)
+	if err != nil {
+		return err
+	}
+	v.SetString(s)
+	return nil
+}
+
+type bytesCodec struct {
+	prefix LengthPrefix
+	max    int
+}
+
+func (c bytesCodec) encode(w *Writer, v reflect.Value) error {
+	return w.WriteLengthPrefixed(c.prefix, v.Bytes())
+}
+
+func (c bytesCodec) decode(r *Reader, v reflect.Value) error {
+	b, err := r.ReadLengthPrefixed(c.prefix, c.max)
+	if err != nil {
+		return err
+	}
+	v.SetBytes(b)
+	return nil
+}
+
+type sliceCodec struct {
+	prefix LengthPrefix
+	max    int
+	elem   codec
+}
+
+func (c sliceCodec) encode(w *Writer, v reflect.Value) error {
+	if err := w.writeLength(c.prefix, v.Len()); err != nil {
+		return err
+	}
+	return encodeElements(w, v, c.elem)
+}
+
+func (c sliceCodec) decode(r *Reader, v reflect.Value) error {
+	length, n, err := r.peekLength(c.prefix, 0)
+	if err != nil {
+		return err
+	}
+	if length > uint64(c.max) {
+		return ErrTooLarge
+	}
+	r.SkipRead(n)
+
+	// every element takes at least a byte, a bogus length can not allocate more than bytes available
+	capacity := int(length)
+	if available := r.Available(); available < capacity {
+		capacity = remaining(available, 0)
+	}
+	s := reflect.MakeSlice(v.Type(), 0, capacity)
+	zero := reflect.Zero(v.Type().Elem())
+	for i := 0; i < int(length); i++ {
+		s = reflect.Append(s, zero)
+		offset := r.ReaderIndex()
+		if err := c.elem.decode(r, s.Index(i)); err != nil {
+			return wrapFieldError(err, fmt.Sprintf("[%d]", i), offset)
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+type arrayCodec struct {
+	elem codec
+}
+
+func (c arrayCodec) encode(w *Writer, v reflect.Value) error {
+	return encodeElements(w, v, c.elem)
+}
+
+func (c arrayCodec) decode(r *Reader, v reflect.Value) error {
+	return decodeElements(r, v, c.elem)
+}
+
+func encodeElements(w *Writer, v reflect.Value, elem codec) error {
+	for i := 0; i < v.Len(); i++ {
+		offset := w.WriterIndex()
+		if err := elem.encode(w, v.Index(i)); err != nil {
+			return wrapFieldError(err, fmt.Sprintf("[%d]", i), offset)
+		}
+	}
+	return nil
+}
+
+func decodeElements(r *Reader, v reflect.Value, elem codec) error {
+	for i := 0; i < v.Len(); i++ {
+		offset := r.ReaderIndex()
+		if err := elem.decode(r, v.Index(i)); err != nil {
+			return wrapFieldError(err, fmt.Sprintf("[%d]", i), offset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package gobuf
+
+import (
+	"errors"
+	"io"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type marshalHeader struct {
+	Magic   uint16 `gobuf:"u16,be"`
+	Version uint8
+	Flags   [2]bool
+}
+
+type marshalItem struct {
+	ID   int    `gobuf:"varint"`
+	Name string `gobuf:"len=u8,max=8"`
+}
+
+type marshalMessage struct {
+	Header  marshalHeader
+	Length  int     `gobuf:"u32"`
+	Offset  int64   `gobuf:"i16,le"`
+	Ratio   float32 `gobuf:"f32,be"`
+	Score   float64
+	Payload []byte `gobuf:"len=u16"`
+	Items   []marshalItem
+	Counts  []uint16 `gobuf:"u16,be,len=u8"`
+	Skipped string   `gobuf:"-"`
+	hidden  int
+}
+
+var _ = Describe("Marshal", func() {
+	message := marshalMessage{
+		Header:  marshalHeader{Magic: 0xcafe, Version: 2, Flags: [2]bool{true, false}},
+		Length:  42,
+		Offset:  -3,
+		Ratio:   1.5,
+		Score:   -2.25,
+		Payload: []byte("hello"),
+		Items:   []marshalItem{{ID: -1, Name: "a"}, {ID: 300, Name: "bc"}},
+		Counts:  []uint16{1, 2},
+		Skipped: "skipped",
+		hidden:  1,
+	}
+
+	It("should marshal and unmarshal", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(Marshal(b.Writer, &message)).To(BeNil())
+
+		Expect(b.Bytes()[:5]).To(Equal([]byte{0xca, 0xfe, 0x02, 0x01, 0x00}))
+		raw := b.Bytes()[9:19]
+		Expect(raw).To(Equal([]byte{0xfd, 0xff, 0x3f, 0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}))
+
+		var out marshalMessage
+		Expect(Unmarshal(b.Reader, &out)).To(BeNil())
+		expected := message
+		expected.Skipped = ""
+		expected.hidden = 0
+		Expect(out).To(Equal(expected))
+		Expect(b.Available()).To(Equal(0))
+	})
+
+	It("should follow buffer order", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)), WithBigEndian())
+		Expect(Marshal(b.Writer, marshalItem{ID: 1, Name: "a"})).To(BeNil())
+		Expect(Marshal(b.Writer, struct{ V uint32 }{1})).To(BeNil())
+		Expect(b.Bytes()[:7]).To(Equal([]byte{0x02, 0x01, 'a', 0x00, 0x00, 0x00, 0x01}))
+	})
+
+	It("should report field path and offset", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		m := message
+		m.Items = []marshalItem{{Name: "a"}, {Name: "too long for u8 prefix" + string(make([]byte, 256))}}
+		err := Marshal(b.Writer, m)
+		var fe *FieldError
+		Expect(errors.As(err, &fe)).To(BeTrue())
+		Expect(fe.Path).To(Equal("Items[1].Name"))
+		Expect(fe.Offset).To(Equal(35))
+		Expect(errors.Is(err, ErrTooLarge)).To(BeTrue())
+
+		b = New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(Marshal(b.Writer, message)).To(BeNil())
+		truncated := New(b.Bytes()[:b.Size()-1])
+		err = Unmarshal(truncated.Reader, &marshalMessage{})
+		Expect(errors.As(err, &fe)).To(BeTrue())
+		Expect(fe.Path).To(Equal("Counts[1]"))
+		Expect(fe.Offset).To(Equal(b.Size() - 2))
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+	})
+
+	It("should reject values out of range", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		err := Marshal(b.Writer, struct {
+			V int `gobuf:"u8"`
+		}{-1})
+		Expect(errors.Is(err, ErrOutOfRange)).To(BeTrue())
+
+		b = New([]byte{0xff})
+		err = Unmarshal(b.Reader, &struct {
+			V int8 `gobuf:"u8"`
+		}{})
+		Expect(errors.Is(err, ErrOutOfRange)).To(BeTrue())
+
+		b = New([]byte{0xff})
+		v := struct {
+			V int16 `gobuf:"i8"`
+		}{}
+		Expect(Unmarshal(b.Reader, &v)).To(BeNil())
+		Expect(v.V).To(Equal(int16(-1)))
+	})
+
+	It("should reject invalid tags and types", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(errors.Is(Marshal(b.Writer, struct {
+			V int `gobuf:"u17"`
+		}{}), ErrInvalidTag)).To(BeTrue())
+		Expect(errors.Is(Marshal(b.Writer, struct {
+			V string `gobuf:"f32"`
+		}{}), ErrInvalidTag)).To(BeTrue())
+		Expect(errors.Is(Marshal(b.Writer, struct {
+			V map[string]int
+		}{}), ErrUnsupportedType)).To(BeTrue())
+		Expect(errors.Is(Marshal(b.Writer, 1), ErrUnsupportedType)).To(BeTrue())
+		Expect(errors.Is(Unmarshal(b.Reader, marshalItem{}), ErrUnsupportedType)).To(BeTrue())
+	})
+
+	It("should limit length when unmarshalling", func() {
+		b := New([]byte{0x01, 0x09, 'a'})
+		err := Unmarshal(b.Reader, &marshalItem{})
+		Expect(errors.Is(err, ErrTooLarge)).To(BeTrue())
+	})
+
+	It("should not allocate slice length before elements are available", func() {
+		var v struct {
+			Values []uint64 `gobuf:"len=u32,be"`
+		}
+		b := New([]byte{0x00, 0x10, 0x00, 0x00, 1, 2, 3})
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		err := Unmarshal(b.Reader, &v)
+		runtime.ReadMemStats(&after)
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+		Expect(after.TotalAlloc - before.TotalAlloc).To(BeNumerically("<", 4096))
+	})
+
+	It("should handle recursive types", func() {
+		type node struct {
+			Value    uint8
+			Children []node `gobuf:"len=u8"`
+		}
+
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		in := node{Value: 1, Children: []node{{Value: 2, Children: []node{}}, {Value: 3, Children: []node{}}}}
+		Expect(Marshal(b.Writer, in)).To(BeNil())
+		Expect(b.Size()).To(Equal(6))
+
+		var out node
+		Expect(Unmarshal(b.Reader, &out)).To(BeNil())
+		Expect(out).To(Equal(in))
+	})
+})
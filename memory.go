@@ -2,6 +2,7 @@ package gobuf
 
 import (
 	"io"
+	"sort"
 )
 
 // Memory used to store raw []byte
@@ -22,44 +23,106 @@ type Memory interface {
 	Reset()
 }
 
+// Discardable memory which can drop bytes from the front more efficiently than reading and writing them back
+type Discardable interface {
+	// Discard drop first n bytes, offsets of remaining bytes are reduced by n
+	Discard(n int)
+}
+
+// Segmented memory which can expose its bytes without copying
+type Segmented interface {
+	// Segments append slices covering n bytes starts at given location to dst, slices are only valid until next write
+	Segments(dst [][]byte, at, n int) [][]byte
+}
+
+// remaining number of bytes from at to end, 0 if at is beyond end
+func remaining(end, at int) int {
+	if at >= end {
+		return 0
+	}
+	return end - at
+}
+
+// moveChunkSize size of chunk used when moving bytes within memory
+const moveChunkSize = 4096
+
+// moveMemory move length bytes starts at from to the front of memory
+func moveMemory(m Memory, from, length int) error {
+	chunk := make([]byte, moveChunkSize)
+	for moved := 0; moved < length; moved += len(chunk) {
+		if remain := length - moved; remain < len(chunk) {
+			chunk = chunk[:remain]
+		}
+		if err := m.Read(from+moved, chunk); err != nil {
+			return err
+		}
+		if err := m.Write(moved, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type SliceMemory struct {
 	buf  []byte
 	grow Grow
+	// written end of bytes written, bytes after it are zero
+	written int
+	// pool slices are taken from and returned to, nil to allocate
+	pool *BufferPool
 }
 
 func NewSliceMemory(buf []byte, grow Grow) *SliceMemory {
 	return &SliceMemory{
-		buf:  buf,
-		grow: grow,
+		buf:     buf,
+		grow:    grow,
+		written: len(buf),
 	}
 }
 
 func (m *SliceMemory) Write(at int, src []byte) error {
+	if at < 0 {
+		return newError("Write", at, len(src), 0, ErrNegativeOffset)
+	}
+
 	end := at + len(src)
 	if end > cap(m.buf) {
 		if m.grow == nil {
-			return ErrOutOfSpace
+			return newError("Write", at, len(src), remaining(cap(m.buf), at), ErrOutOfSpace)
 		}
 		finalCap := m.grow(cap(m.buf), end)
-		newBuf := make([]byte, finalCap)
+		newBuf := m.alloc(finalCap)
 		copy(newBuf, m.buf)
+		m.release()
 		m.buf = newBuf
 	}
 
 	copy(m.buf[at:end], src)
+	if end > m.written {
+		m.written = end
+	}
 	return nil
 }
 
 func (m *SliceMemory) Read(at int, dst []byte) error {
+	if at < 0 {
+		return newError("Read", at, len(dst), 0, ErrNegativeOffset)
+	}
+
 	end := at + len(dst)
 	if end > cap(m.buf) {
-		return io.EOF
+		return newError("Read", at, len(dst), remaining(cap(m.buf), at), io.EOF)
 	}
 
 	copy(dst, m.buf[at:end])
 	return nil
 }
 
+// Segments implements Segmented
+func (m *SliceMemory) Segments(dst [][]byte, at, n int) [][]byte {
+	return append(dst, m.buf[at:at+n])
+}
+
 func (m *SliceMemory) Bytes() []byte {
 	out := make([]byte, len(m.buf))
 	copy(out, m.buf)
@@ -70,155 +133,211 @@ func (m *SliceMemory) Length() int {
 	return len(m.buf)
 }
 
+// Discard implements Discardable, remaining written bytes are moved to the front and capacity is kept
+func (m *SliceMemory) Discard(n int) {
+	if n > m.written {
+		n = m.written
+	}
+	copied := copy(m.buf[:m.written], m.buf[n:m.written])
+	zero(m.buf[copied:m.written])
+	m.written = copied
+}
+
 func (m *SliceMemory) Reset() {
-	m.buf = make([]byte, m.grow(0, 1))
+	m.release()
+	m.buf = m.alloc(m.grow(0, 1))
+	m.written = 0
 }
 
-type listMemoryNode struct {
-	buf  []byte
-	next *listMemoryNode
+func (m *SliceMemory) alloc(n int) []byte {
+	if m.pool == nil {
+		return make([]byte, n)
+	}
+	return m.pool.getChunk(n)
 }
 
+// release return slice to pool
+func (m *SliceMemory) release() {
+	if m.pool != nil && m.buf != nil {
+		m.pool.putChunk(m.buf)
+	}
+	m.buf = nil
+}
+
+// ListMemory backed by a list of nodes, growing never copies written bytes.
+// starts of nodes are indexed so locations are found with binary search
 type ListMemory struct {
-	start *listMemoryNode
-	grow  Grow
+	nodes [][]byte
+	// starts location of first byte of each node
+	starts []int
+	// capacity total size of nodes
+	capacity int
+	// length end of bytes written
+	length int
+	grow   Grow
+	// pool nodes are taken from and returned to, nil to allocate
+	pool *BufferPool
 }
 
+// NewListMemory create a ListMemory, buf is used as first node and its bytes are written
 func NewListMemory(buf []byte, grow Grow) *ListMemory {
-	return &ListMemory{
-		start: &listMemoryNode{
-			buf: buf,
-		},
+	m := &ListMemory{
 		grow: grow,
 	}
+	m.append(buf)
+	m.length = len(buf)
+	return m
 }
 
-func (m *ListMemory) Write(at int, src []byte) error {
-	total := len(src)
-	end := total + at
-	capacity := 0
-	node := m.start
-	prev := node
-	wrote := 0
-	index := 0
-
-	for wrote < total {
-		// reached end of list, allocate new node
-		if node == nil {
-			newCapacity := m.grow(capacity, end)
-			node = &listMemoryNode{
-				buf: make([]byte, newCapacity-capacity),
-			}
-			prev.next = node
-			capacity = newCapacity
-		}
-
-		nodeCapacity := cap(node.buf)
-		capacity += nodeCapacity
+// append add a node to end of list
+func (m *ListMemory) append(buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+	m.nodes = append(m.nodes, buf)
+	m.starts = append(m.starts, m.capacity)
+	m.capacity += len(buf)
+}
 
-		// skip until cursor is at the correct node
-		if (index + nodeCapacity) < at {
-			index += nodeCapacity
-			prev = node
-			node = node.next
-			continue
-		}
+// find index of node holding given location, which must be within capacity
+func (m *ListMemory) find(at int) int {
+	return sort.SearchInts(m.starts, at+1) - 1
+}
 
-		offset := at - index
-		if offset < 0 {
-			offset = 0
-		}
+func (m *ListMemory) Write(at int, src []byte) error {
+	if at < 0 {
+		return newError("Write", at, len(src), 0, ErrNegativeOffset)
+	}
+	if len(src) == 0 {
+		return nil
+	}
 
-		available := nodeCapacity - offset
-		remain := total - wrote
-		// current node is enough to write rest data
-		if remain <= available {
-			copy(node.buf[offset:], src[wrote:])
-			break
+	end := at + len(src)
+	if end > m.capacity {
+		if m.grow == nil {
+			return newError("Write", at, len(src), remaining(m.capacity, at), ErrOutOfSpace)
 		}
+		m.append(m.alloc(m.grow(m.capacity, end) - m.capacity))
+	}
 
-		copy(node.buf[offset:], src[wrote:wrote+available])
-		wrote += available
-		index += nodeCapacity
-		prev = node
-		node = node.next
+	wrote := 0
+	for i := m.find(at); wrote < len(src); i++ {
+		wrote += copy(m.nodes[i][at+wrote-m.starts[i]:], src[wrote:])
 	}
 
+	if end > m.length {
+		m.length = end
+	}
 	return nil
 }
 
 func (m *ListMemory) Read(at int, dst []byte) error {
-	total := len(dst)
-	node := m.start
-	read := 0
-	index := 0
-	length := 0
-
-	for read < total {
-		if node == nil {
-			return io.EOF
-		}
+	if at < 0 {
+		return newError("Read", at, len(dst), 0, ErrNegativeOffset)
+	}
+	if at+len(dst) > m.length {
+		return newError("Read", at, len(dst), remaining(m.length, at), io.EOF)
+	}
+	if len(dst) == 0 {
+		return nil
+	}
 
-		nodeCapacity := cap(node.buf)
-		length += nodeCapacity
+	read := 0
+	for i := m.find(at); read < len(dst); i++ {
+		read += copy(dst[read:], m.nodes[i][at+read-m.starts[i]:])
+	}
+	return nil
+}
 
-		// skip until cursor is at the correct node
-		if (index + nodeCapacity) < at {
-			index += nodeCapacity
-			node = node.next
-			continue
-		}
+// Segments implements Segmented, one slice per node
+func (m *ListMemory) Segments(dst [][]byte, at, n int) [][]byte {
+	if n <= 0 {
+		return dst
+	}
 
-		offset := at - index
-		if offset < 0 {
-			offset = 0
+	for i := m.find(at); i < len(m.nodes) && n > 0; i++ {
+		node := m.nodes[i][at-m.starts[i]:]
+		if len(node) > n {
+			node = node[:n]
 		}
+		dst = append(dst, node)
+		at += len(node)
+		n -= len(node)
+	}
+	return dst
+}
 
-		available := nodeCapacity - offset
-		remain := total - read
-		// current node is enough to read rest data
-		if remain <= available {
-			copy(dst[read:], node.buf[offset:offset+remain])
+// Bytes copy of bytes written, capacity of nodes beyond is not included
+func (m *ListMemory) Bytes() []byte {
+	out := make([]byte, m.length)
+	index := 0
+	for _, node := range m.nodes {
+		if index >= m.length {
 			break
 		}
-
-		copy(dst[read:], node.buf[offset:])
-		read += available
-		index += nodeCapacity
-		node = node.next
+		index += copy(out[index:], node)
 	}
+	return out
+}
 
-	return nil
+// Length end of bytes written
+func (m *ListMemory) Length() int {
+	return m.length
 }
 
-func (m *ListMemory) Bytes() []byte {
-	length := m.Length()
+// Discard implements Discardable, whole nodes before n are released and no bytes are copied
+func (m *ListMemory) Discard(n int) {
+	if n > m.length {
+		n = m.length
+	}
+	m.length -= n
+
+	dropped := 0
+	for dropped < len(m.nodes) && n >= len(m.nodes[dropped]) {
+		n -= len(m.nodes[dropped])
+		m.free(m.nodes[dropped])
+		m.nodes[dropped] = nil
+		dropped++
+	}
 
-	out := make([]byte, length)
-	index := 0
-	node := m.start
-	for node != nil {
-		copy(out[index:], node.buf)
-		index += cap(node.buf)
-		node = node.next
+	nodes := m.nodes[dropped:]
+	if len(nodes) > 0 {
+		nodes[0] = nodes[0][n:]
 	}
 
-	return out
+	m.nodes = m.nodes[:0]
+	m.starts = m.starts[:0]
+	m.capacity = 0
+	for _, node := range nodes {
+		m.append(node)
+	}
 }
 
-func (m *ListMemory) Length() int {
-	length := 0
-	node := m.start
-	for node != nil {
-		length += cap(node.buf)
-		node = node.next
+func (m *ListMemory) Reset() {
+	m.release()
+	m.append(m.alloc(m.grow(0, 1)))
+}
+
+func (m *ListMemory) alloc(n int) []byte {
+	if m.pool == nil {
+		return make([]byte, n)
 	}
+	return m.pool.getChunk(n)
+}
 
-	return length
+func (m *ListMemory) free(b []byte) {
+	if m.pool != nil && b != nil {
+		m.pool.putChunk(b)
+	}
 }
 
-func (m *ListMemory) Reset() {
-	m.start = &listMemoryNode{
-		buf: make([]byte, m.grow(0, 1)),
+// release return every node to pool
+func (m *ListMemory) release() {
+	for _, node := range m.nodes {
+		m.free(node)
 	}
+	m.nodes = nil
+	m.starts = nil
+	m.capacity = 0
+	m.length = 0
 }
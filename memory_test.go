@@ -24,7 +24,7 @@ var _ = Describe("Memory", func() {
 
 			b = make([]byte, 3)
 			err = m.Read(3, b)
-			Expect(err).To(Equal(io.EOF))
+			Expect(err).To(MatchError(io.EOF))
 		})
 
 		It("should write", func() {
@@ -40,7 +40,7 @@ var _ = Describe("Memory", func() {
 			Expect(string(m.buf)).To(Equal("woaaa"))
 
 			err = m.Write(2, []byte("aaaa"))
-			Expect(err).To(Equal(ErrOutOfSpace))
+			Expect(err).To(MatchError(ErrOutOfSpace))
 		})
 	})
 
@@ -74,6 +74,21 @@ var _ = Describe("Memory", func() {
 			Expect(string(m.buf)).To(Equal("hello\x00world\x00\x00\x00\x00\x00aaaa"))
 			Expect(cap(m.buf)).To(Equal(20))
 		})
+
+		It("should discard only written bytes", func() {
+			m := NewSliceMemory(nil, FixedGrow(1<<20))
+			Expect(m.Write(0, []byte("hello"))).To(BeNil())
+			Expect(m.written).To(Equal(5))
+
+			m.Discard(2)
+			Expect(m.written).To(Equal(3))
+			Expect(m.Length()).To(Equal(1 << 20))
+			Expect(string(m.buf[:5])).To(Equal("llo\x00\x00"))
+
+			m.Discard(10)
+			Expect(m.written).To(Equal(0))
+			Expect(m.Bytes()).To(Equal(make([]byte, 1<<20)))
+		})
 	})
 
 	Describe("ListMemory", func() {
@@ -82,59 +97,54 @@ var _ = Describe("Memory", func() {
 
 			err := m.Write(0, []byte("world"))
 			Expect(err).To(BeNil())
-			Expect(string(m.start.buf)).To(Equal("world"))
+			Expect(string(m.nodes[0])).To(Equal("world"))
 			Expect(m.Length()).To(Equal(5))
 
 			err = m.Write(0, []byte("hello world"))
 			Expect(err).To(BeNil())
-			Expect(string(m.Bytes())).To(Equal("hello world\x00\x00\x00\x00"))
-			Expect(len(m.start.buf)).To(Equal(5))
-			Expect(string(m.start.buf)).To(Equal("hello"))
-			Expect(len(m.start.next.buf)).To(Equal(10))
-			Expect(string(m.start.next.buf)).To(Equal(" world\x00\x00\x00\x00"))
-			Expect(m.Length()).To(Equal(15))
+			Expect(string(m.Bytes())).To(Equal("hello world"))
+			Expect(len(m.nodes[0])).To(Equal(5))
+			Expect(string(m.nodes[0])).To(Equal("hello"))
+			Expect(len(m.nodes[1])).To(Equal(10))
+			Expect(string(m.nodes[1])).To(Equal(" world\x00\x00\x00\x00"))
+			Expect(m.Length()).To(Equal(11))
 
 			err = m.Write(11, []byte("aaaa"))
 			Expect(err).To(BeNil())
 			Expect(string(m.Bytes())).To(Equal("hello worldaaaa"))
-			Expect(len(m.start.buf)).To(Equal(5))
-			Expect(string(m.start.buf)).To(Equal("hello"))
-			Expect(len(m.start.next.buf)).To(Equal(10))
-			Expect(string(m.start.next.buf)).To(Equal(" worldaaaa"))
+			Expect(len(m.nodes[0])).To(Equal(5))
+			Expect(string(m.nodes[0])).To(Equal("hello"))
+			Expect(len(m.nodes[1])).To(Equal(10))
+			Expect(string(m.nodes[1])).To(Equal(" worldaaaa"))
 
 			err = m.Write(0, []byte("01234567890123456789"))
 			Expect(err).To(BeNil())
 			Expect(string(m.Bytes())).To(Equal("01234567890123456789"))
-			Expect(len(m.start.buf)).To(Equal(5))
-			Expect(string(m.start.buf)).To(Equal("01234"))
-			Expect(len(m.start.next.buf)).To(Equal(10))
-			Expect(string(m.start.next.buf)).To(Equal("5678901234"))
-			Expect(len(m.start.next.next.buf)).To(Equal(5))
-			Expect(string(m.start.next.next.buf)).To(Equal("56789"))
+			Expect(len(m.nodes[0])).To(Equal(5))
+			Expect(string(m.nodes[0])).To(Equal("01234"))
+			Expect(len(m.nodes[1])).To(Equal(10))
+			Expect(string(m.nodes[1])).To(Equal("5678901234"))
+			Expect(len(m.nodes[2])).To(Equal(5))
+			Expect(string(m.nodes[2])).To(Equal("56789"))
 			Expect(m.Length()).To(Equal(20))
 
 			m = NewListMemory([]byte("hello"), FixedGrow(5))
 			err = m.Write(6, []byte("world"))
 			Expect(err).To(BeNil())
-			Expect(string(m.Bytes())).To(Equal("hello\x00world\x00\x00\x00\x00"))
-			Expect(len(m.start.buf)).To(Equal(5))
-			Expect(string(m.start.buf)).To(Equal("hello"))
-			Expect(len(m.start.next.buf)).To(Equal(10))
-			Expect(string(m.start.next.buf)).To(Equal("\x00world\x00\x00\x00\x00"))
+			Expect(string(m.Bytes())).To(Equal("hello\x00world"))
+			Expect(m.Length()).To(Equal(11))
+			Expect(len(m.nodes[0])).To(Equal(5))
+			Expect(string(m.nodes[0])).To(Equal("hello"))
+			Expect(len(m.nodes[1])).To(Equal(10))
+			Expect(string(m.nodes[1])).To(Equal("\x00world\x00\x00\x00\x00"))
 		})
 
 		It("should read", func() {
-			m := &ListMemory{
-				start: &listMemoryNode{
-					buf: []byte("01234"),
-					next: &listMemoryNode{
-						buf: []byte("56789"),
-						next: &listMemoryNode{
-							buf: []byte("0123456789"),
-						},
-					},
-				},
-			}
+			m := NewListMemory([]byte("01234"), FixedGrow(5))
+			Expect(m.Write(5, []byte("56789"))).To(BeNil())
+			Expect(m.Write(10, []byte("0123456789"))).To(BeNil())
+			Expect(m.nodes).To(HaveLen(3))
+			Expect(m.starts).To(Equal([]int{0, 5, 10}))
 
 			b := make([]byte, 5)
 			err := m.Read(0, b)
@@ -143,7 +153,7 @@ var _ = Describe("Memory", func() {
 
 			b = make([]byte, 30)
 			err = m.Read(0, b)
-			Expect(err).To(Equal(io.EOF))
+			Expect(err).To(MatchError(io.EOF))
 
 			b = make([]byte, 20)
 			err = m.Read(0, b)
@@ -164,6 +174,34 @@ var _ = Describe("Memory", func() {
 			err = m.Read(12, b)
 			Expect(err).To(BeNil())
 			Expect(string(b)).To(Equal("23456"))
+
+			Expect(m.Read(-1, b)).To(MatchError(ErrNegativeOffset))
+		})
+
+		It("should track written length", func() {
+			m := NewListMemory(nil, FixedGrow(8))
+			Expect(m.Length()).To(Equal(0))
+			Expect(m.Bytes()).To(Equal([]byte{}))
+
+			Expect(m.Write(0, []byte("abc"))).To(BeNil())
+			Expect(m.Length()).To(Equal(3))
+			Expect(m.capacity).To(Equal(8))
+			Expect(m.Read(2, make([]byte, 2))).To(MatchError(io.EOF))
+			Expect(m.Segments(nil, 1, 2)).To(Equal([][]byte{[]byte("bc")}))
+
+			Expect(m.Write(6, []byte("defg"))).To(BeNil())
+			Expect(m.Length()).To(Equal(10))
+			Expect(m.Bytes()).To(Equal([]byte("abc\x00\x00\x00defg")))
+			Expect(m.Segments(nil, 5, 5)).To(Equal([][]byte{[]byte("\x00de"), []byte("fg")}))
+
+			m.Discard(7)
+			Expect(m.Length()).To(Equal(3))
+			Expect(m.starts).To(Equal([]int{0, 1}))
+			Expect(m.Bytes()).To(Equal([]byte("efg")))
+
+			m.Reset()
+			Expect(m.Length()).To(Equal(0))
+			Expect(m.capacity).To(Equal(8))
 		})
 	})
 })
@@ -0,0 +1,315 @@
+// Package memorytest checks gobuf.Memory implementations behave like built-in memories.
+//
+// a conforming memory starts empty, grows on writes beyond its length, keeps Length at least the end of bytes written,
+// reads zero from locations which were never written, fails with io.EOF when reading beyond Length,
+// rejects negative offsets with an error matching gobuf.ErrNegativeOffset, and returns copies from Bytes.
+package memorytest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/joesonw/gobuf"
+)
+
+// randomSeed seed of randomized differential test, fixed so failures can be reproduced
+const randomSeed = 1
+
+// randomOps number of operations of randomized differential test
+const randomOps = 2000
+
+// RunConformance run conformance tests against memories created by newMemory, each test gets a new empty memory
+func RunConformance(t *testing.T, newMemory func() gobuf.Memory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, m gobuf.Memory)
+	}{
+		{"Sequential", testSequential},
+		{"RandomOffset", testRandomOffset},
+		{"CrossBoundary", testCrossBoundary},
+		{"ZeroLength", testZeroLength},
+		{"OutOfRange", testOutOfRange},
+		{"Bytes", testBytes},
+		{"Reset", testReset},
+		{"Differential", testDifferential},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m := newMemory()
+			if m == nil {
+				t.Fatal("newMemory returned nil")
+			}
+			if m.Length() != 0 {
+				t.Fatalf("new memory has length %d, want 0", m.Length())
+			}
+			test.fn(t, m)
+		})
+	}
+}
+
+// pattern n bytes which differ at every location of a short period
+func pattern(seed, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(seed + i*7 + 1)
+	}
+	return b
+}
+
+func mustWrite(t *testing.T, m gobuf.Memory, at int, src []byte) {
+	t.Helper()
+	if err := m.Write(at, src); err != nil {
+		t.Fatalf("Write(%d, %d bytes): %v", at, len(src), err)
+	}
+	if len(src) > 0 && m.Length() < at+len(src) {
+		t.Fatalf("Length() = %d after Write(%d, %d bytes), want at least %d", m.Length(), at, len(src), at+len(src))
+	}
+}
+
+func expectRead(t *testing.T, m gobuf.Memory, at int, want []byte) {
+	t.Helper()
+	got := make([]byte, len(want))
+	if err := m.Read(at, got); err != nil {
+		t.Fatalf("Read(%d, %d bytes): %v", at, len(want), err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Read(%d, %d bytes) = %x, want %x", at, len(want), got, want)
+	}
+}
+
+func expectEOF(t *testing.T, m gobuf.Memory, at, n int) {
+	t.Helper()
+	if err := m.Read(at, make([]byte, n)); !errors.Is(err, io.EOF) {
+		t.Fatalf("Read(%d, %d bytes) beyond length %d: %v, want io.EOF", at, n, m.Length(), err)
+	}
+}
+
+// expectContent bytes of memory start with want and are zero after
+func expectContent(t *testing.T, m gobuf.Memory, want []byte) {
+	t.Helper()
+	out := m.Bytes()
+	if len(out) != m.Length() {
+		t.Fatalf("len(Bytes()) = %d, want Length() %d", len(out), m.Length())
+	}
+	if len(out) < len(want) {
+		t.Fatalf("len(Bytes()) = %d, want at least %d", len(out), len(want))
+	}
+	if !bytes.Equal(out[:len(want)], want) {
+		t.Fatalf("Bytes() = %x, want prefix %x", out, want)
+	}
+	for i, b := range out[len(want):] {
+		if b != 0 {
+			t.Fatalf("Bytes()[%d] = %d beyond written bytes, want 0", len(want)+i, b)
+		}
+	}
+}
+
+func testSequential(t *testing.T, m gobuf.Memory) {
+	var want []byte
+	for i := 1; i <= 64; i++ {
+		chunk := pattern(i, i)
+		mustWrite(t, m, len(want), chunk)
+		want = append(want, chunk...)
+	}
+
+	at := 0
+	for i := 1; i <= 64; i++ {
+		expectRead(t, m, at, want[at:at+i])
+		at += i
+	}
+	expectRead(t, m, 0, want)
+	expectContent(t, m, want)
+}
+
+func testRandomOffset(t *testing.T, m gobuf.Memory) {
+	want := make([]byte, 1024)
+	for _, at := range []int{512, 3, 1000, 0, 700, 128, 1023} {
+		chunk := pattern(at, 1)
+		if at+16 <= len(want) {
+			chunk = pattern(at, 16)
+		}
+		mustWrite(t, m, at, chunk)
+		copy(want[at:], chunk)
+	}
+
+	expectRead(t, m, 0, want)
+	// never written bytes between chunks are zero
+	expectRead(t, m, 20, make([]byte, 100))
+	expectContent(t, m, want)
+
+	// overwrite in place
+	mustWrite(t, m, 510, pattern(99, 8))
+	copy(want[510:], pattern(99, 8))
+	expectRead(t, m, 500, want[500:530])
+}
+
+func testCrossBoundary(t *testing.T, m gobuf.Memory) {
+	// odd sizes so writes and reads straddle any power of two or fixed size chunk boundaries
+	want := pattern(3, 4099)
+	for at := 0; at < len(want); at += 37 {
+		end := at + 37
+		if end > len(want) {
+			end = len(want)
+		}
+		mustWrite(t, m, at, want[at:end])
+	}
+
+	for _, size := range []int{1, 2, 3, 31, 64, 65, 1023, 1025, 4099} {
+		for at := 0; at+size <= len(want); at += size*3 + 1 {
+			expectRead(t, m, at, want[at:at+size])
+		}
+	}
+
+	// one write spanning many chunks
+	big := pattern(11, 9001)
+	mustWrite(t, m, 17, big)
+	expectRead(t, m, 17, big)
+	expectRead(t, m, 0, want[:17])
+}
+
+func testZeroLength(t *testing.T, m gobuf.Memory) {
+	if err := m.Write(0, nil); err != nil {
+		t.Fatalf("Write(0, nil): %v", err)
+	}
+	if err := m.Read(0, nil); err != nil {
+		t.Fatalf("Read(0, nil): %v", err)
+	}
+
+	mustWrite(t, m, 0, pattern(1, 10))
+	length := m.Length()
+	if err := m.Write(5, []byte{}); err != nil {
+		t.Fatalf("Write(5, empty): %v", err)
+	}
+	if err := m.Write(length, []byte{}); err != nil {
+		t.Fatalf("Write(%d, empty): %v", length, err)
+	}
+	if m.Length() != length {
+		t.Fatalf("Length() = %d after empty writes, want %d", m.Length(), length)
+	}
+	for _, at := range []int{0, 5, length} {
+		if err := m.Read(at, []byte{}); err != nil {
+			t.Fatalf("Read(%d, empty): %v", at, err)
+		}
+	}
+	expectRead(t, m, 0, pattern(1, 10))
+}
+
+func testOutOfRange(t *testing.T, m gobuf.Memory) {
+	expectEOF(t, m, 0, 1)
+
+	mustWrite(t, m, 0, pattern(1, 100))
+	length := m.Length()
+	expectEOF(t, m, length, 1)
+	expectEOF(t, m, length-1, 2)
+	expectEOF(t, m, length+1000, 10)
+	expectEOF(t, m, 0, length+1)
+
+	if err := m.Read(-1, make([]byte, 1)); !errors.Is(err, gobuf.ErrNegativeOffset) {
+		t.Fatalf("Read(-1) returned %v, want %v", err, gobuf.ErrNegativeOffset)
+	}
+	if err := m.Write(-1, []byte{1}); !errors.Is(err, gobuf.ErrNegativeOffset) {
+		t.Fatalf("Write(-1) returned %v, want %v", err, gobuf.ErrNegativeOffset)
+	}
+	expectRead(t, m, 0, pattern(1, 100))
+}
+
+func testBytes(t *testing.T, m gobuf.Memory) {
+	want := pattern(5, 300)
+	mustWrite(t, m, 0, want)
+
+	out := m.Bytes()
+	for i := range out {
+		out[i] = 0xff
+	}
+	expectRead(t, m, 0, want)
+
+	out = m.Bytes()
+	mustWrite(t, m, 0, pattern(6, 300))
+	if !bytes.Equal(out[:len(want)], want) {
+		t.Fatal("Bytes() changed by a later write, want a copy")
+	}
+}
+
+func testReset(t *testing.T, m gobuf.Memory) {
+	mustWrite(t, m, 0, pattern(1, 5000))
+	m.Reset()
+	expectContent(t, m, nil)
+
+	want := pattern(2, 100)
+	mustWrite(t, m, 50, want)
+	expectRead(t, m, 0, make([]byte, 50))
+	expectRead(t, m, 50, want)
+	expectContent(t, m, append(make([]byte, 50), want...))
+
+	m.Reset()
+	m.Reset()
+	expectContent(t, m, nil)
+	expectEOF(t, m, m.Length(), 1)
+}
+
+// testDifferential apply random operations to memory and a plain slice, memory must agree with slice
+func testDifferential(t *testing.T, m gobuf.Memory) {
+	t.Logf("seed %d", randomSeed)
+	rnd := rand.New(rand.NewSource(randomSeed))
+
+	// ref bytes written so far, memory may report a larger length with zeros after them
+	var ref []byte
+	discardable, _ := m.(gobuf.Discardable)
+	segmented, _ := m.(gobuf.Segmented)
+
+	for op := 0; op < randomOps; op++ {
+		switch r := rnd.Intn(100); {
+		case r < 45:
+			at := rnd.Intn(len(ref) + 64)
+			src := make([]byte, 1+rnd.Intn(200))
+			rnd.Read(src)
+			mustWrite(t, m, at, src)
+			if end := at + len(src); end > len(ref) {
+				ref = append(ref, make([]byte, end-len(ref))...)
+			}
+			copy(ref[at:], src)
+
+		case r < 85:
+			if len(ref) == 0 {
+				continue
+			}
+			at := rnd.Intn(len(ref))
+			n := rnd.Intn(len(ref) - at + 1)
+			expectRead(t, m, at, ref[at:at+n])
+
+			if segmented != nil {
+				var got []byte
+				for _, segment := range segmented.Segments(nil, at, n) {
+					got = append(got, segment...)
+				}
+				if !bytes.Equal(got, ref[at:at+n]) {
+					t.Fatalf("op %d: Segments(%d, %d) = %x, want %x", op, at, n, got, ref[at:at+n])
+				}
+			}
+
+		case r < 90:
+			expectEOF(t, m, m.Length()+rnd.Intn(10), 1+rnd.Intn(10))
+
+		case r < 95:
+			if discardable == nil || len(ref) == 0 {
+				continue
+			}
+			n := rnd.Intn(len(ref) + 1)
+			discardable.Discard(n)
+			ref = ref[n:]
+
+		case r < 97:
+			m.Reset()
+			ref = nil
+
+		default:
+			expectContent(t, m, ref)
+		}
+	}
+	expectContent(t, m, ref)
+}
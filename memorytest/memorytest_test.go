@@ -0,0 +1,53 @@
+package memorytest
+
+import (
+	"testing"
+
+	"github.com/joesonw/gobuf"
+)
+
+func TestSliceMemory(t *testing.T) {
+	RunConformance(t, func() gobuf.Memory {
+		return gobuf.NewSliceMemory(nil, gobuf.FixedGrow(7))
+	})
+}
+
+func TestListMemory(t *testing.T) {
+	RunConformance(t, func() gobuf.Memory {
+		return gobuf.NewListMemory(nil, gobuf.FixedGrow(7))
+	})
+}
+
+func TestPooledMemory(t *testing.T) {
+	pool := gobuf.NewBufferPool()
+	t.Run("SliceMemory", func(t *testing.T) {
+		RunConformance(t, func() gobuf.Memory {
+			return pool.NewSliceMemory(nil)
+		})
+	})
+	t.Run("ListMemory", func(t *testing.T) {
+		RunConformance(t, func() gobuf.Memory {
+			return pool.NewListMemory(gobuf.FixedGrow(64))
+		})
+	})
+}
+
+func TestTieredMemory(t *testing.T) {
+	dir := t.TempDir()
+	for _, threshold := range []int{512, 0} {
+		threshold := threshold
+		name := "SliceTier"
+		if threshold == 0 {
+			name = "FileTier"
+		}
+		t.Run(name, func(t *testing.T) {
+			RunConformance(t, func() gobuf.Memory {
+				m := gobuf.NewTieredMemory(threshold, gobuf.FixedGrow(64), dir)
+				t.Cleanup(func() {
+					m.Close()
+				})
+				return m
+			})
+		})
+	}
+}
@@ -0,0 +1,168 @@
+//go:build linux
+// +build linux
+
+package gobuf
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// MmapMemory memory backed by a file mapped with mmap, file grows with ftruncate and remaps when writing beyond its end
+type MmapMemory struct {
+	file   *os.File
+	data   []byte
+	grow   Grow
+	closed bool
+}
+
+// NewMmapMemory map whole file f, which must be opened for both reading and writing.
+// f is still owned by caller, and should be closed after memory is closed
+func NewMmapMemory(f *os.File, grow Grow) (*MmapMemory, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MmapMemory{
+		file: f,
+		grow: grow,
+	}
+	if err := m.remap(int(info.Size())); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// remap unmap current mapping and map file again with given size
+func (m *MmapMemory) remap(size int) error {
+	if err := m.unmap(); err != nil {
+		return err
+	}
+
+	// mapping zero bytes is not allowed, keep it unmapped until first write
+	if size == 0 {
+		return nil
+	}
+
+	data, err := syscall.Mmap(int(m.file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	m.data = data
+	return nil
+}
+
+func (m *MmapMemory) unmap() error {
+	if m.data == nil {
+		return nil
+	}
+
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	return err
+}
+
+// resize truncate file to given size and remap it
+func (m *MmapMemory) resize(size int) error {
+	if err := m.unmap(); err != nil {
+		return err
+	}
+	if err := m.file.Truncate(int64(size)); err != nil {
+		return err
+	}
+	return m.remap(size)
+}
+
+func (m *MmapMemory) Write(at int, src []byte) error {
+	if m.closed {
+		return ErrClosed
+	}
+	if at < 0 {
+		return ErrNegativeOffset
+	}
+
+	end := at + len(src)
+	if end > len(m.data) {
+		if m.grow == nil {
+			return ErrOutOfSpace
+		}
+		if err := m.resize(m.grow(len(m.data), end)); err != nil {
+			return err
+		}
+	}
+
+	copy(m.data[at:end], src)
+	return nil
+}
+
+func (m *MmapMemory) Read(at int, dst []byte) error {
+	if m.closed {
+		return ErrClosed
+	}
+	if at < 0 {
+		return ErrNegativeOffset
+	}
+
+	end := at + len(dst)
+	if end > len(m.data) {
+		return io.EOF
+	}
+
+	copy(dst, m.data[at:end])
+	return nil
+}
+
+func (m *MmapMemory) Bytes() []byte {
+	out := make([]byte, len(m.data))
+	copy(out, m.data)
+	return out
+}
+
+func (m *MmapMemory) Length() int {
+	return len(m.data)
+}
+
+// Reset truncate file, then grow it to initial size
+func (m *MmapMemory) Reset() {
+	if m.closed {
+		return
+	}
+
+	size := 0
+	if m.grow != nil {
+		size = m.grow(0, 1)
+	}
+	// drop old content before growing again, so new space is zeroed
+	_ = m.resize(0)
+	_ = m.resize(size)
+}
+
+// Sync flush mapped data to file
+func (m *MmapMemory) Sync() error {
+	if len(m.data) == 0 {
+		return nil
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.data[0])), uintptr(len(m.data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close sync and unmap file, underlying file is left open
+func (m *MmapMemory) Close() error {
+	if m.closed {
+		return nil
+	}
+
+	err := m.Sync()
+	if unmapErr := m.unmap(); err == nil {
+		err = unmapErr
+	}
+	m.closed = true
+	return err
+}
@@ -0,0 +1,94 @@
+//go:build linux
+// +build linux
+
+package gobuf
+
+import (
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MmapMemory", func() {
+	var f *os.File
+
+	BeforeEach(func() {
+		var err error
+		f, err = os.CreateTemp("", "gobuf-mmap")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(f.Close()).To(BeNil())
+		Expect(os.Remove(f.Name())).To(BeNil())
+	})
+
+	It("should read existing file", func() {
+		_, err := f.WriteString("hello world")
+		Expect(err).To(BeNil())
+
+		m, err := NewMmapMemory(f, nil)
+		Expect(err).To(BeNil())
+		defer m.Close()
+
+		buf := New(nil, WithMemory(m))
+		Expect(buf.Size()).To(Equal(11))
+
+		s, err := buf.PeekString(5, 6)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("world"))
+
+		s, err = buf.ReadString(11)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("hello world"))
+
+		Expect(m.Read(8, make([]byte, 4))).To(Equal(io.EOF))
+		Expect(m.Write(8, []byte("1234"))).To(Equal(ErrOutOfSpace))
+	})
+
+	It("should grow file", func() {
+		m, err := NewMmapMemory(f, FixedGrow(4096))
+		Expect(err).To(BeNil())
+		Expect(m.Length()).To(Equal(0))
+
+		buf := New(nil, WithMemory(m))
+		Expect(buf.WriteString("hello")).To(BeNil())
+		Expect(m.Length()).To(Equal(4096))
+
+		Expect(m.Write(5000, []byte("world"))).To(BeNil())
+		Expect(m.Length()).To(Equal(8192))
+		Expect(m.Sync()).To(BeNil())
+
+		info, err := f.Stat()
+		Expect(err).To(BeNil())
+		Expect(info.Size()).To(Equal(int64(8192)))
+
+		b := make([]byte, 5)
+		_, err = f.ReadAt(b, 5000)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(Equal("world"))
+
+		Expect(m.Close()).To(BeNil())
+		Expect(m.Write(0, []byte("a"))).To(Equal(ErrClosed))
+
+		_, err = f.ReadAt(b, 0)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(Equal("hello"))
+	})
+
+	It("should reset", func() {
+		m, err := NewMmapMemory(f, FixedGrow(4096))
+		Expect(err).To(BeNil())
+		defer m.Close()
+
+		Expect(m.Write(0, []byte("hello"))).To(BeNil())
+		m.Reset()
+		Expect(m.Length()).To(Equal(4096))
+
+		b := make([]byte, 5)
+		Expect(m.Read(0, b)).To(BeNil())
+		Expect(b).To(Equal(make([]byte, 5)))
+	})
+})
@@ -22,6 +22,20 @@ func WithLinkedListMemory(grow Grow) OptionFunc {
 	}
 }
 
+// WithAutoDiscard discard read bytes before writing once reader index reaches threshold
+func WithAutoDiscard(threshold int) OptionFunc {
+	return func(b *Buffer, buf []byte) {
+		b.autoDiscard = threshold
+	}
+}
+
+// WithReadOnly fail writes with ErrReadOnly, bytes of buf are never changed
+func WithReadOnly() OptionFunc {
+	return func(b *Buffer, buf []byte) {
+		b.readOnly = true
+	}
+}
+
 func WithLittleEndian() OptionFunc {
 	return func(b *Buffer, buf []byte) {
 		b.order = binary.LittleEndian
@@ -33,3 +47,29 @@ func WithBigEndian() OptionFunc {
 		b.order = binary.BigEndian
 	}
 }
+
+type WriterOptionFunc func(w *IOWriter)
+
+// WithWriteBuffer buffer writes in mem and flush once threshold bytes are buffered, 0 to only flush on Flush and Close.
+// buffered chunks of ListMemory are written to a net.Conn with a single writev
+func WithWriteBuffer(mem Memory, threshold int) WriterOptionFunc {
+	return func(w *IOWriter) {
+		if mem == nil {
+			mem = NewSliceMemory(nil, FixedGrow(defaultHoldGrow))
+		}
+		w.mem = mem
+		w.buffered = true
+		w.threshold = threshold
+	}
+}
+
+type ReaderOptionFunc func(r *IOReader)
+
+// WithReadAhead min number of bytes requested from underlying reader at once, bytes read past are released in chunks of this size
+func WithReadAhead(n int) ReaderOptionFunc {
+	return func(r *IOReader) {
+		if n > 0 {
+			r.chunk = n
+		}
+	}
+}
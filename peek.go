@@ -2,6 +2,8 @@ package gobuf
 
 import (
 	"encoding/binary"
+	"errors"
+	"io"
 	"math"
 )
 
@@ -13,6 +15,11 @@ type Peekable interface {
 type Peeker struct {
 	Peekable
 	index int
+	// reader index saved by MarkReader
+	mark   int
+	marked bool
+	// scratch space for decoding numbers without allocation
+	scratch [8]byte
 }
 
 func NewPeeker(p Peekable) *Peeker {
@@ -27,6 +34,7 @@ func (p *Peeker) ReaderIndex() int {
 
 func (p *Peeker) ResetReader() {
 	p.index = 0
+	p.marked = false
 }
 
 func (p *Peeker) Peek(offset int, dst []byte) (n int, err error) {
@@ -37,12 +45,12 @@ func (p *Peeker) Peek(offset int, dst []byte) (n int, err error) {
 // PeekBool peek a bool
 func (p *Peeker) PeekBool(offset ...int) (bool, error) {
 	b, err := p.PeekByte(offset...)
-	return b == 1, err
+	return b == 1, withOp(err, "PeekBool")
 }
 
 // PeekByte peek a byte
 func (p *Peeker) PeekByte(offset ...int) (byte, error) {
-	b, err := p.PeekBytes(1, offset...)
+	b, err := p.peekScratch("PeekByte", 1, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -50,26 +58,98 @@ func (p *Peeker) PeekByte(offset ...int) (byte, error) {
 	return b[0], nil
 }
 
-// PeekBytes peek given length of bytes
+// peekChunkSize max number of bytes PeekBytes allocates ahead of data when size of Peekable is unknown
+const peekChunkSize = 64 << 10
+
+// sizedPeekable a Peekable holding all of its bytes, nothing at or beyond peekSize can be peeked
+type sizedPeekable interface {
+	peekSize() int
+}
+
+// PeekBytes peek given length of bytes. n is checked against bytes available before allocating,
+// streams are peeked in growing chunks so a bogus length never allocates much more than there is
 func (p *Peeker) PeekBytes(n int, offset ...int) ([]byte, error) {
-	o := 0
-	if len(offset) > 0 {
-		o = offset[0]
+	o := peekOffset(offset)
+	at := p.index + o
+	if n < 0 {
+		return nil, newError("PeekBytes", at, n, 0, ErrOutOfRange)
 	}
-	b := make([]byte, n)
-	_, err := p.Peek(o, b)
-	if err != nil {
-		return nil, err
+	if s, ok := p.Peekable.(sizedPeekable); ok && at >= 0 {
+		if available := remaining(s.peekSize(), at); available < n {
+			err := ErrShortBuffer
+			if available == 0 {
+				err = io.EOF
+			}
+			return nil, newError("PeekBytes", at, n, available, err)
+		}
+	}
+
+	if n <= peekChunkSize {
+		b := make([]byte, n)
+		if err := p.peekFull("PeekBytes", b, offset); err != nil {
+			return nil, err
+		}
+		return b, nil
 	}
 
+	var b []byte
+	for len(b) < n {
+		start := len(b)
+		end := 2 * start
+		if end < peekChunkSize {
+			end = peekChunkSize
+		}
+		if end > n {
+			end = n
+		}
+		grown := make([]byte, end)
+		copy(grown, b)
+		b = grown
+
+		if err := p.peekFull("PeekBytes", b[start:], []int{o + start}); err != nil {
+			e := err.(*Error)
+			e.Offset, e.Want, e.Available = at, n, start+e.Available
+			if e.Err == io.EOF && start > 0 {
+				e.Err = ErrShortBuffer
+			}
+			return nil, e
+		}
+	}
 	return b, nil
 }
 
+// peekOffset offset of optional offset argument, 0 if not given
+func peekOffset(offset []int) int {
+	if len(offset) > 0 {
+		return offset[0]
+	}
+	return 0
+}
+
+// peekFull peek exactly len(dst) bytes at offset into dst, errors are reported as operation op
+func (p *Peeker) peekFull(op string, dst []byte, offset []int) error {
+	o := peekOffset(offset)
+	n, err := p.Peek(o, dst)
+	if n == len(dst) {
+		return nil
+	}
+	if err == nil || (errors.Is(err, io.EOF) && n > 0) {
+		err = ErrShortBuffer
+	}
+	return newError(op, p.index+o, len(dst), n, err)
+}
+
+// peekScratch peek n bytes into scratch space, returned slice is only valid until next peek
+func (p *Peeker) peekScratch(op string, n int, offset []int) ([]byte, error) {
+	b := p.scratch[:n]
+	return b, p.peekFull(op, b, offset)
+}
+
 // PeekString peek given length of string
 func (p *Peeker) PeekString(n int, offset ...int) (string, error) {
 	b, err := p.PeekBytes(n, offset...)
 	if err != nil {
-		return "", err
+		return "", withOp(err, "PeekString")
 	}
 	return string(b), nil
 }
@@ -78,7 +158,7 @@ func (p *Peeker) PeekString(n int, offset ...int) (string, error) {
 func (p *Peeker) PeekUint8(offset ...int) (uint8, error) {
 	b, err := p.PeekByte(offset...)
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "PeekUint8")
 	}
 
 	return b, nil
@@ -86,7 +166,7 @@ func (p *Peeker) PeekUint8(offset ...int) (uint8, error) {
 
 // PeekUint16 peek uint16
 func (p *Peeker) PeekUint16(offset ...int) (uint16, error) {
-	b, err := p.PeekBytes(2, offset...)
+	b, err := p.peekScratch("PeekUint16", 2, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -96,7 +176,7 @@ func (p *Peeker) PeekUint16(offset ...int) (uint16, error) {
 
 // PeekUint32 peek uint32
 func (p *Peeker) PeekUint32(offset ...int) (uint32, error) {
-	b, err := p.PeekBytes(4, offset...)
+	b, err := p.peekScratch("PeekUint32", 4, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -106,7 +186,7 @@ func (p *Peeker) PeekUint32(offset ...int) (uint32, error) {
 
 // PeekUint64 peek uint64
 func (p *Peeker) PeekUint64(offset ...int) (uint64, error) {
-	b, err := p.PeekBytes(8, offset...)
+	b, err := p.peekScratch("PeekUint64", 8, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -118,7 +198,7 @@ func (p *Peeker) PeekUint64(offset ...int) (uint64, error) {
 func (p *Peeker) PeekInt8(offset ...int) (int8, error) {
 	b, err := p.PeekByte(offset...)
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "PeekInt8")
 	}
 
 	return int8(b), nil
@@ -126,7 +206,7 @@ func (p *Peeker) PeekInt8(offset ...int) (int8, error) {
 
 // PeekInt16 peek int16
 func (p *Peeker) PeekInt16(offset ...int) (int16, error) {
-	b, err := p.PeekBytes(2, offset...)
+	b, err := p.peekScratch("PeekInt16", 2, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -136,7 +216,7 @@ func (p *Peeker) PeekInt16(offset ...int) (int16, error) {
 
 // PeekInt32 peek int32
 func (p *Peeker) PeekInt32(offset ...int) (int32, error) {
-	b, err := p.PeekBytes(4, offset...)
+	b, err := p.peekScratch("PeekInt32", 4, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -146,7 +226,7 @@ func (p *Peeker) PeekInt32(offset ...int) (int32, error) {
 
 // PeekInt64 peek int64
 func (p *Peeker) PeekInt64(offset ...int) (int64, error) {
-	b, err := p.PeekBytes(8, offset...)
+	b, err := p.peekScratch("PeekInt64", 8, offset)
 	if err != nil {
 		return 0, err
 	}
@@ -158,7 +238,7 @@ func (p *Peeker) PeekInt64(offset ...int) (int64, error) {
 func (p *Peeker) PeekFloat32(offset ...int) (float32, error) {
 	u, err := p.PeekUint32(offset...)
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "PeekFloat32")
 	}
 	return math.Float32frombits(u), nil
 }
@@ -167,7 +247,100 @@ func (p *Peeker) PeekFloat32(offset ...int) (float32, error) {
 func (p *Peeker) PeekFloat64(offset ...int) (float64, error) {
 	u, err := p.PeekUint64(offset...)
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "PeekFloat64")
 	}
 	return math.Float64frombits(u), nil
 }
+
+// PeekUvarint peek unsigned varint, returns value and number of bytes it takes
+func (p *Peeker) PeekUvarint(offset ...int) (uint64, int, error) {
+	return p.peekULEB128("PeekUvarint", binary.MaxVarintLen64, offset)
+}
+
+// PeekVarint peek zigzag encoded varint, returns value and number of bytes it takes
+func (p *Peeker) PeekVarint(offset ...int) (int64, int, error) {
+	u, n, err := p.PeekUvarint(offset...)
+	if err != nil {
+		return 0, 0, withOp(err, "PeekVarint")
+	}
+	return decodeZigZag(u), n, nil
+}
+
+// PeekULEB128 peek unsigned LEB128, returns value and number of bytes it takes
+func (p *Peeker) PeekULEB128(offset ...int) (uint64, int, error) {
+	return p.peekULEB128("PeekULEB128", 0, offset)
+}
+
+// PeekSLEB128 peek signed LEB128, returns value and number of bytes it takes
+func (p *Peeker) PeekSLEB128(offset ...int) (int64, int, error) {
+	return p.peekSLEB128("PeekSLEB128", offset)
+}
+
+// PeekProtoVarint peek protobuf int32/int64 varint, returns value and number of bytes it takes
+func (p *Peeker) PeekProtoVarint(offset ...int) (int64, int, error) {
+	u, n, err := p.PeekUvarint(offset...)
+	if err != nil {
+		return 0, 0, withOp(err, "PeekProtoVarint")
+	}
+	return int64(u), n, nil
+}
+
+// peekAvailable check n bytes can be peeked at offset without allocating them, only the last one is peeked
+func (p *Peeker) peekAvailable(op string, n, offset int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	_, err := p.PeekByte(offset + n - 1)
+	if err == nil || !errors.Is(err, io.EOF) {
+		return withOp(err, op)
+	}
+
+	at := p.index + offset
+	available := 0
+	if r, ok := p.Peekable.(Readable); ok {
+		available = remaining(r.Size(), at)
+	}
+	if available == 0 {
+		return newError(op, at, n, 0, io.EOF)
+	}
+	return newError(op, at, n, available, ErrShortBuffer)
+}
+
+// PeekLengthPrefixed peek length-prefixed bytes, length larger than max is rejected before allocating.
+// returns bytes and total number of bytes it takes including prefix
+func (p *Peeker) PeekLengthPrefixed(prefix LengthPrefix, max int, offset ...int) ([]byte, int, error) {
+	o := peekOffset(offset)
+	length, n, err := p.peekLength(prefix, o)
+	if err != nil {
+		return nil, 0, withOp(err, "PeekLengthPrefixed")
+	}
+
+	if max < 0 || length > uint64(max) {
+		want := maxInt
+		if length < uint64(maxInt) {
+			want = int(length)
+		}
+		return nil, 0, newError("PeekLengthPrefixed", p.index+o+n, want, max, ErrTooLarge)
+	}
+	if err := p.peekAvailable("PeekLengthPrefixed", int(length), o+n); err != nil {
+		return nil, 0, err
+	}
+
+	b, err := p.PeekBytes(int(length), o+n)
+	if err != nil {
+		return nil, 0, withOp(err, "PeekLengthPrefixed")
+	}
+
+	return b, n + int(length), nil
+}
+
+// PeekLengthPrefixedString peek length-prefixed string, length larger than max is rejected before allocating.
+// returns string and total number of bytes it takes including prefix
+func (p *Peeker) PeekLengthPrefixedString(prefix LengthPrefix, max int, offset ...int) (string, int, error) {
+	b, n, err := p.PeekLengthPrefixed(prefix, max, offset...)
+	if err != nil {
+		return "", 0, withOp(err, "PeekLengthPrefixedString")
+	}
+	return string(b), n, nil
+}
@@ -0,0 +1,234 @@
+package gobuf
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// minPoolShift smallest size class is 64 bytes
+	minPoolShift = 6
+	// maxPoolShift largest size class is 16MB, larger slices are not pooled
+	maxPoolShift = 24
+	poolClasses  = maxPoolShift - minPoolShift + 1
+	// poolSearchClasses number of larger classes searched for a free buffer
+	poolSearchClasses = 4
+)
+
+// poolClass size class holding at least n bytes, -1 if too large to be pooled
+func poolClass(n int) int {
+	if n <= 1<<minPoolShift {
+		return 0
+	}
+	shift := bits.Len(uint(n - 1))
+	if shift > maxPoolShift {
+		return -1
+	}
+	return shift - minPoolShift
+}
+
+// poolPutClass size class of a slice with given capacity, -1 if it is not exactly a class size
+func poolPutClass(capacity int) int {
+	if capacity < 1<<minPoolShift || capacity&(capacity-1) != 0 {
+		return -1
+	}
+	shift := bits.Len(uint(capacity)) - 1
+	if shift > maxPoolShift {
+		return -1
+	}
+	return shift - minPoolShift
+}
+
+// poolGrow grow to next size class
+func poolGrow(size, want int) int {
+	if class := poolClass(want); class >= 0 {
+		return 1 << uint(class+minPoolShift)
+	}
+	return want
+}
+
+// PoolStats counters of a BufferPool
+type PoolStats struct {
+	// Gets number of buffers and chunks requested
+	Gets uint64
+	// Misses number of requests which had to allocate
+	Misses uint64
+	// Puts number of buffers and chunks returned to pool
+	Puts uint64
+	// Drops number of returned buffers and chunks which could not be pooled
+	Drops uint64
+}
+
+// BufferPool recycle buffers and memory chunks in power of two size classes
+type BufferPool struct {
+	stats   PoolStats
+	buffers [poolClasses]sync.Pool
+	chunks  [poolClasses]sync.Pool
+}
+
+func NewBufferPool() *BufferPool {
+	return &BufferPool{}
+}
+
+// DefaultBufferPool pool shared by default
+var DefaultBufferPool = NewBufferPool()
+
+// Stats snapshot of counters
+func (p *BufferPool) Stats() PoolStats {
+	return PoolStats{
+		Gets:   atomic.LoadUint64(&p.stats.Gets),
+		Misses: atomic.LoadUint64(&p.stats.Misses),
+		Puts:   atomic.LoadUint64(&p.stats.Puts),
+		Drops:  atomic.LoadUint64(&p.stats.Drops),
+	}
+}
+
+// Get get an empty buffer able to hold at least size bytes without growing, memory grows with chunks of pool.
+// Release it once it is no longer used. options replacing memory (WithMemory, WithAutoGrowMemory, WithLinkedListMemory)
+// bypass recycling, pooled memory goes back to pool right away and buffer is not pooled on Release
+func (p *BufferPool) Get(size int, options ...OptionFunc) *Buffer {
+	atomic.AddUint64(&p.stats.Gets, 1)
+
+	var b *Buffer
+	if class := poolClass(size); class >= 0 {
+		// buffers grown while in use are pooled in larger classes
+		for c := class; b == nil && c < poolClasses && c <= class+poolSearchClasses; c++ {
+			b, _ = p.buffers[c].Get().(*Buffer)
+		}
+	}
+	if b == nil {
+		atomic.AddUint64(&p.stats.Misses, 1)
+		mem := p.NewSliceMemory(make([]byte, poolGrow(0, size)))
+		// nothing is written to a new slice yet
+		mem.written = 0
+		b = &Buffer{
+			mem:  mem,
+			pool: p,
+		}
+		b.Peeker = NewPeeker(b)
+		b.Reader = NewRead(b, b.Peeker)
+		b.Writer = NewWriter(b)
+	}
+
+	b.released = false
+	b.order = binary.LittleEndian
+	mem := b.mem
+	for _, option := range options {
+		option(b, nil)
+	}
+	if b.mem != mem {
+		if r, ok := mem.(releasable); ok {
+			r.release()
+		}
+		b.pool = nil
+	}
+	return b
+}
+
+// put return a released buffer, it is only pooled if its memory is a pooled SliceMemory of exact class size
+func (p *BufferPool) put(b *Buffer) {
+	m, ok := b.mem.(*SliceMemory)
+	class := -1
+	if ok && m.pool == p {
+		class = poolPutClass(cap(m.buf))
+	}
+	if class < 0 {
+		atomic.AddUint64(&p.stats.Drops, 1)
+		if r, ok := b.mem.(releasable); ok {
+			r.release()
+		}
+		return
+	}
+
+	m.buf = m.buf[:cap(m.buf)]
+	zero(m.buf)
+	m.written = 0
+	b.size = 0
+	b.discarded = 0
+	b.autoDiscard = 0
+	b.markSize = 0
+	b.readOnly = false
+	b.views = nil
+	b.ResetReader()
+	b.ResetWriter()
+
+	atomic.AddUint64(&p.stats.Puts, 1)
+	p.buffers[class].Put(b)
+}
+
+// getChunk get a chunk holding at least n bytes, capacity is rounded up to size class
+func (p *BufferPool) getChunk(n int) []byte {
+	atomic.AddUint64(&p.stats.Gets, 1)
+
+	class := poolClass(n)
+	if class >= 0 {
+		if chunk, ok := p.chunks[class].Get().(*[]byte); ok {
+			return *chunk
+		}
+	}
+
+	atomic.AddUint64(&p.stats.Misses, 1)
+	return make([]byte, poolGrow(0, n))
+}
+
+// putChunk return a chunk, it is zeroed and only pooled if its capacity is exactly a class size
+func (p *BufferPool) putChunk(b []byte) {
+	class := poolPutClass(cap(b))
+	if class < 0 {
+		atomic.AddUint64(&p.stats.Drops, 1)
+		return
+	}
+
+	b = b[:cap(b)]
+	zero(b)
+	atomic.AddUint64(&p.stats.Puts, 1)
+	p.chunks[class].Put(&b)
+}
+
+// NewSliceMemory create a SliceMemory which grows with chunks of pool, replaced chunks go back to pool
+func (p *BufferPool) NewSliceMemory(buf []byte) *SliceMemory {
+	return &SliceMemory{
+		buf:  buf,
+		grow: poolGrow,
+		pool: p,
+	}
+}
+
+// NewListMemory create a ListMemory whose nodes come from and go back to chunks of pool
+func (p *BufferPool) NewListMemory(grow Grow) *ListMemory {
+	m := NewListMemory(nil, grow)
+	m.pool = p
+	return m
+}
+
+// Release return memory and buffer to their pool, views stop holding back discarding of their parent.
+// buffer must not be used afterwards, releasing it again does nothing
+func (buf *Buffer) Release() {
+	if buf.released {
+		return
+	}
+	buf.released = true
+
+	if buf.pool != nil {
+		buf.pool.put(buf)
+		return
+	}
+
+	if r, ok := buf.mem.(releasable); ok {
+		r.release()
+	}
+}
+
+// zero clear bytes so pooled data does not leak into next user
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// releasable memory holding pooled chunks
+type releasable interface {
+	release()
+}
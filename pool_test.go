@@ -0,0 +1,122 @@
+package gobuf
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BufferPool", func() {
+	It("should round sizes to power of two classes", func() {
+		Expect(poolClass(0)).To(Equal(0))
+		Expect(poolClass(64)).To(Equal(0))
+		Expect(poolClass(65)).To(Equal(1))
+		Expect(poolClass(1 << 24)).To(Equal(poolClasses - 1))
+		Expect(poolClass(1<<24 + 1)).To(Equal(-1))
+		Expect(poolPutClass(128)).To(Equal(1))
+		Expect(poolPutClass(100)).To(Equal(-1))
+		Expect(poolPutClass(32)).To(Equal(-1))
+	})
+
+	It("should recycle buffers", func() {
+		p := NewBufferPool()
+		b := p.Get(100, WithBigEndian())
+		Expect(b.Size()).To(Equal(0))
+		Expect(b.Order()).To(Equal(binary.BigEndian))
+		Expect(b.WriteUint32(1)).To(BeNil())
+		b.SkipRead(2)
+		b.Release()
+
+		stats := p.Stats()
+		Expect(stats.Gets).To(Equal(uint64(1)))
+		Expect(stats.Misses).To(Equal(uint64(1)))
+		Expect(stats.Puts).To(Equal(uint64(1)))
+
+		// sync.Pool may drop items at any time, only check state of what comes back
+		b = p.Get(128)
+		Expect(b.Size()).To(Equal(0))
+		Expect(b.ReaderIndex()).To(Equal(0))
+		Expect(b.WriterIndex()).To(Equal(0))
+		Expect(b.Order()).To(Equal(binary.LittleEndian))
+		Expect(b.Bytes()[:4]).To(Equal([]byte{0, 0, 0, 0}))
+	})
+
+	It("should ignore buffers released twice", func() {
+		p := NewBufferPool()
+		b := p.Get(100)
+		b.Release()
+		b.Release()
+		Expect(p.Stats().Puts).To(Equal(uint64(1)))
+
+		// sync.Pool may drop items at any time, buffer must not come back twice
+		first := p.Get(100)
+		second := p.Get(100)
+		Expect(first).NotTo(BeIdenticalTo(second))
+		Expect(first.WriteString("first")).To(BeNil())
+		Expect(second.WriteString("second")).To(BeNil())
+		Expect(first.ReadString(5)).To(Equal("first"))
+	})
+
+	It("should not recycle buffers whose memory is replaced by options", func() {
+		p := NewBufferPool()
+		b := p.Get(100, WithAutoGrowMemory(FixedGrow(8)))
+		// pooled slice is returned as chunk right away
+		Expect(p.Stats()).To(Equal(PoolStats{Gets: 1, Misses: 1, Puts: 1}))
+
+		Expect(b.WriteString("hello world")).To(BeNil())
+		b.Release()
+		Expect(p.Stats()).To(Equal(PoolStats{Gets: 1, Misses: 1, Puts: 1}))
+	})
+
+	It("should reset options of recycled buffers", func() {
+		p := NewBufferPool()
+		b := p.Get(100, WithReadOnly(), WithAutoDiscard(8))
+		Expect(b.WriteUint32(1)).To(MatchError(ErrReadOnly))
+		b.Release()
+
+		// sync.Pool may drop items at any time, a recycled or new buffer must both be writable
+		b = p.Get(100)
+		Expect(b.readOnly).To(BeFalse())
+		Expect(b.autoDiscard).To(Equal(0))
+		Expect(b.WriteUint32(1)).To(BeNil())
+		Expect(b.Size()).To(Equal(4))
+	})
+
+	It("should grow with pooled chunks", func() {
+		p := NewBufferPool()
+		b := p.Get(64)
+		Expect(b.WriteBytes(make([]byte, 100))).To(BeNil())
+		Expect(len(b.Bytes())).To(Equal(128))
+		stats := p.Stats()
+		Expect(stats.Gets).To(Equal(uint64(2)))
+		// replaced 64 bytes slice went back to pool
+		Expect(stats.Puts).To(Equal(uint64(1)))
+
+		b.Release()
+		Expect(p.Stats().Puts).To(Equal(uint64(2)))
+	})
+
+	It("should take list nodes from pool", func() {
+		p := NewBufferPool()
+		m := p.NewListMemory(FixedGrow(100))
+		b := New(nil, WithMemory(m))
+		Expect(b.WriteBytes(make([]byte, 300))).To(BeNil())
+		Expect(p.Stats().Gets).To(BeNumerically(">", 0))
+
+		gets := p.Stats().Gets
+		b.Release()
+		Expect(p.Stats().Puts).To(Equal(gets))
+		Expect(m.Length()).To(Equal(0))
+	})
+
+	It("should drop buffers which can not be pooled", func() {
+		p := NewBufferPool()
+		// larger than largest size class
+		b := p.Get(1<<maxPoolShift + 1)
+		b.Release()
+		// both buffer and its slice are dropped
+		Expect(p.Stats().Drops).To(Equal(uint64(2)))
+		Expect(p.Stats().Puts).To(Equal(uint64(0)))
+	})
+})
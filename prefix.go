@@ -0,0 +1,77 @@
+package gobuf
+
+import (
+	"math"
+)
+
+// LengthPrefix encoding of length in front of length-prefixed bytes
+type LengthPrefix int
+
+const (
+	PrefixUint8 LengthPrefix = iota + 1
+	PrefixUint16
+	PrefixUint32
+	PrefixUint64
+	PrefixUvarint
+)
+
+// maxInt max value of int
+const maxInt = int(^uint(0) >> 1)
+
+// maxLength max length can be represented by the prefix
+func (p LengthPrefix) maxLength() uint64 {
+	switch p {
+	case PrefixUint8:
+		return math.MaxUint8
+	case PrefixUint16:
+		return math.MaxUint16
+	case PrefixUint32:
+		return math.MaxUint32
+	default:
+		return math.MaxUint64
+	}
+}
+
+// writeLength write length as prefix
+func (w *Writer) writeLength(prefix LengthPrefix, n int) error {
+	if uint64(n) > prefix.maxLength() {
+		return newError("WriteLengthPrefixed", w.index, n, int(prefix.maxLength()), ErrTooLarge)
+	}
+
+	switch prefix {
+	case PrefixUint8:
+		return w.WriteUint8(uint8(n))
+	case PrefixUint16:
+		return w.WriteUint16(uint16(n))
+	case PrefixUint32:
+		return w.WriteUint32(uint32(n))
+	case PrefixUint64:
+		return w.WriteUint64(uint64(n))
+	case PrefixUvarint:
+		return w.WriteUvarint(uint64(n))
+	default:
+		return ErrInvalidPrefix
+	}
+}
+
+// peekLength peek length prefix at offset, returns length and size of the prefix
+func (p *Peeker) peekLength(prefix LengthPrefix, offset int) (uint64, int, error) {
+	switch prefix {
+	case PrefixUint8:
+		u, err := p.PeekUint8(offset)
+		return uint64(u), 1, err
+	case PrefixUint16:
+		u, err := p.PeekUint16(offset)
+		return uint64(u), 2, err
+	case PrefixUint32:
+		u, err := p.PeekUint32(offset)
+		return uint64(u), 4, err
+	case PrefixUint64:
+		u, err := p.PeekUint64(offset)
+		return u, 8, err
+	case PrefixUvarint:
+		return p.PeekUvarint(offset)
+	default:
+		return 0, 0, ErrInvalidPrefix
+	}
+}
@@ -0,0 +1,106 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LengthPrefixed", func() {
+	It("should write/read with every prefix", func() {
+		prefixes := []struct {
+			prefix LengthPrefix
+			size   int
+		}{
+			{PrefixUint8, 1},
+			{PrefixUint16, 2},
+			{PrefixUint32, 4},
+			{PrefixUint64, 8},
+			{PrefixUvarint, 1},
+		}
+
+		for _, p := range prefixes {
+			b := New(nil, WithAutoGrowMemory(FixedGrow(32)), WithBigEndian())
+			Expect(b.WriteLengthPrefixed(p.prefix, []byte("hello"))).To(BeNil())
+			Expect(b.WriteLengthPrefixedString(p.prefix, "world")).To(BeNil())
+			Expect(b.WriteLengthPrefixedString(p.prefix, "")).To(BeNil())
+			Expect(b.Size()).To(Equal(3*p.size + 10))
+
+			out, n, err := b.PeekLengthPrefixed(p.prefix, 5)
+			Expect(err).To(BeNil())
+			Expect(string(out)).To(Equal("hello"))
+			Expect(n).To(Equal(p.size + 5))
+
+			s, n, err := b.PeekLengthPrefixedString(p.prefix, 5, p.size+5)
+			Expect(err).To(BeNil())
+			Expect(s).To(Equal("world"))
+			Expect(n).To(Equal(p.size + 5))
+
+			out, err = b.ReadLengthPrefixed(p.prefix, 5)
+			Expect(err).To(BeNil())
+			Expect(string(out)).To(Equal("hello"))
+
+			s, err = b.ReadLengthPrefixedString(p.prefix, 5)
+			Expect(err).To(BeNil())
+			Expect(s).To(Equal("world"))
+
+			s, err = b.ReadLengthPrefixedString(p.prefix, 5)
+			Expect(err).To(BeNil())
+			Expect(s).To(Equal(""))
+			Expect(b.Available()).To(Equal(0))
+		}
+	})
+
+	It("should reject length larger than max", func() {
+		b := New([]byte{0xff, 0xff, 0xff, 0xff, 'a'})
+		_, err := b.ReadLengthPrefixed(PrefixUint32, 1024)
+		Expect(err).To(MatchError(ErrTooLarge))
+		Expect(b.ReaderIndex()).To(Equal(0))
+
+		b = New([]byte{0x02, 'a', 'b'})
+		_, err = b.ReadLengthPrefixedString(PrefixUvarint, 1)
+		Expect(err).To(MatchError(ErrTooLarge))
+
+		s, err := b.ReadLengthPrefixedString(PrefixUvarint, 2)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("ab"))
+	})
+
+	It("should not allocate length claimed by prefix before body is available", func() {
+		header := []byte{0x01, 0x00, 0x00, 0x00, 'a', 'b', 'c'}
+		r := Read(bytes.NewReader(header), binary.BigEndian, NewSliceMemory(nil, FixedGrow(64)), WithReadAhead(64))
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := r.ReadLengthPrefixed(PrefixUint32, 1<<24)
+		runtime.ReadMemStats(&after)
+		Expect(err).To(Equal(&Error{Op: "ReadLengthPrefixed", Offset: 4, Want: 1 << 24, Available: 3, Err: ErrShortBuffer}))
+		Expect(after.TotalAlloc - before.TotalAlloc).To(BeNumerically("<", 4096))
+		Expect(r.ReaderIndex()).To(Equal(0))
+	})
+
+	It("should reject value too long for prefix", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(b.WriteLengthPrefixedString(PrefixUint8, strings.Repeat("a", 256))).To(MatchError(ErrTooLarge))
+		Expect(b.Size()).To(Equal(0))
+	})
+
+	It("should report truncated body", func() {
+		b := New([]byte{0x05, 'a', 'b'})
+		_, err := b.ReadLengthPrefixed(PrefixUint8, 16)
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(b.ReaderIndex()).To(Equal(0))
+	})
+
+	It("should reject invalid prefix", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(b.WriteLengthPrefixed(LengthPrefix(0), []byte("a"))).To(Equal(ErrInvalidPrefix))
+		_, err := b.ReadLengthPrefixed(LengthPrefix(0), 1)
+		Expect(err).To(Equal(ErrInvalidPrefix))
+	})
+})
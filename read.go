@@ -2,6 +2,8 @@ package gobuf
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"math"
 )
 
@@ -21,9 +23,17 @@ func NewRead(r Readable, p *Peeker) *Reader {
 	}
 }
 
+// readNotifiable a Readable which is told when reader index advances
+type readNotifiable interface {
+	readerSkipped(index int)
+}
+
 // SkipRead advance read index
 func (r *Reader) SkipRead(n int) {
 	r.Peeker.index += n
+	if rn, ok := r.Readable.(readNotifiable); ok {
+		rn.readerSkipped(r.Peeker.index)
+	}
 }
 
 // Available available bytes to read
@@ -34,36 +44,50 @@ func (r *Reader) Available() int {
 // Read io.Reader
 func (r *Reader) Read(dst []byte) (n int, err error) {
 	n, err = r.Peek(0, dst)
-	if err == nil {
+	if n > 0 {
 		r.SkipRead(n)
+		// end of data is reported by next read
+		if err == io.EOF {
+			err = nil
+		}
 	}
 	return
 }
 
 // ReadBool read a bool
 func (r *Reader) ReadBool() (bool, error) {
-	b, err := r.ReadByte()
+	b, err := r.readByte("ReadBool")
 	return b == 1, err
 }
 
-// PeekByte read a byte
+// PeekByte read a byte, implements io.ByteReader so end of data is a bare io.EOF
 func (r *Reader) ReadByte() (byte, error) {
-	b, err := r.ReadBytes(1)
+	b, err := r.readByte("ReadByte")
+	if errors.Is(err, io.EOF) {
+		return 0, io.EOF
+	}
+	return b, err
+}
+
+// readByte read a byte, errors are reported as operation op
+func (r *Reader) readByte(op string) (byte, error) {
+	b, err := r.PeekByte()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, op)
 	}
 
-	return b[0], nil
+	r.SkipRead(1)
+	return b, nil
 }
 
 // ReadBytes read given length of bytes
 func (r *Reader) ReadBytes(n int) ([]byte, error) {
-	b := make([]byte, n)
-	_, err := r.Read(b)
+	b, err := r.PeekBytes(n)
 	if err != nil {
-		return nil, err
+		return nil, withOp(err, "ReadBytes")
 	}
 
+	r.SkipRead(n)
 	return b, nil
 }
 
@@ -71,7 +95,7 @@ func (r *Reader) ReadBytes(n int) ([]byte, error) {
 func (r *Reader) ReadString(n int) (string, error) {
 	b, err := r.ReadBytes(n)
 	if err != nil {
-		return "", err
+		return "", withOp(err, "ReadString")
 	}
 
 	return string(b), nil
@@ -79,81 +103,86 @@ func (r *Reader) ReadString(n int) (string, error) {
 
 // ReadUint8 read uint8
 func (r *Reader) ReadUint8() (uint8, error) {
-	b, err := r.ReadByte()
-	return b, err
+	return r.readByte("ReadUint8")
 }
 
 // ReadUint16 read uint16
 func (r *Reader) ReadUint16() (uint16, error) {
-	b, err := r.ReadBytes(2)
+	v, err := r.PeekUint16()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadUint16")
 	}
 
-	return r.Order().Uint16(b), nil
+	r.SkipRead(2)
+	return v, nil
 }
 
 // ReadUint32 read uint32
 func (r *Reader) ReadUint32() (uint32, error) {
-	b, err := r.ReadBytes(4)
+	v, err := r.PeekUint32()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadUint32")
 	}
 
-	return r.Order().Uint32(b), nil
+	r.SkipRead(4)
+	return v, nil
 }
 
 // ReadUint64 read uint64
 func (r *Reader) ReadUint64() (uint64, error) {
-	b, err := r.ReadBytes(8)
+	v, err := r.PeekUint64()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadUint64")
 	}
 
-	return r.Order().Uint64(b), nil
+	r.SkipRead(8)
+	return v, nil
 }
 
 // ReadInt8 read int8
 func (r *Reader) ReadInt8() (int8, error) {
-	b, err := r.ReadByte()
+	b, err := r.readByte("ReadInt8")
 	return int8(b), err
 }
 
 // ReadInt16 read int16
 func (r *Reader) ReadInt16() (int16, error) {
-	b, err := r.ReadBytes(2)
+	v, err := r.PeekInt16()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadInt16")
 	}
 
-	return int16(r.Order().Uint16(b)), nil
+	r.SkipRead(2)
+	return v, nil
 }
 
 // ReadInt32 read int32
 func (r *Reader) ReadInt32() (int32, error) {
-	b, err := r.ReadBytes(4)
+	v, err := r.PeekInt32()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadInt32")
 	}
 
-	return int32(r.Order().Uint32(b)), nil
+	r.SkipRead(4)
+	return v, nil
 }
 
 // ReadInt64 read int64
 func (r *Reader) ReadInt64() (int64, error) {
-	b, err := r.ReadBytes(8)
+	v, err := r.PeekInt64()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadInt64")
 	}
 
-	return int64(r.Order().Uint64(b)), nil
+	r.SkipRead(8)
+	return v, nil
 }
 
 // ReadFloat32 read float32
 func (r *Reader) ReadFloat32() (float32, error) {
 	u, err := r.ReadUint32()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadFloat32")
 	}
 	return math.Float32frombits(u), nil
 }
@@ -162,7 +191,7 @@ func (r *Reader) ReadFloat32() (float32, error) {
 func (r *Reader) ReadFloat64() (float64, error) {
 	u, err := r.ReadUint64()
 	if err != nil {
-		return 0, err
+		return 0, withOp(err, "ReadFloat64")
 	}
 	return math.Float64frombits(u), nil
 }
@@ -170,23 +199,14 @@ func (r *Reader) ReadFloat64() (float64, error) {
 //nolint:gocritic
 // ReadUntil read until any delimiter matches, than skip delimiter and return. otherwise, return false
 func (r *Reader) ReadUntil(delims ...[]byte) ([]byte, bool, error) {
-	// short circuit
-	short := true
-	for _, delim := range delims {
-		// if any delim is shorter than available data, means we can short, no need to short
-		if len(delim) <= r.Available() {
-			short = false
-		}
-	}
-
-	if short {
-		return nil, false, nil
-	}
-
 	for _, delim := range delims {
 		out, err := r.PeekBytes(len(delim))
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// a delim longer than remaining data can not match
+			continue
+		}
 		if err != nil {
-			return nil, false, err
+			return nil, false, withOp(err, "ReadUntil")
 		}
 		if bytes.Equal(out, delim) {
 			r.SkipRead(len(delim))
@@ -195,27 +215,96 @@ func (r *Reader) ReadUntil(delims ...[]byte) ([]byte, bool, error) {
 	}
 
 	var out []byte
-	index := r.ReaderIndex()
-	offset := 0
-	size := r.Size()
-
-	for index < size {
+	// peek until end of data rather than Size, so streams are read as needed
+	for offset := 0; ; offset++ {
 		b, err := r.PeekByte(offset)
+		if errors.Is(err, io.EOF) {
+			return nil, false, nil
+		}
 		if err != nil {
-			return nil, false, err
+			return nil, false, withOp(err, "ReadUntil")
 		}
 		out = append(out, b)
 
 		for _, delim := range delims {
 			length := len(delim)
+			if length > len(out) {
+				continue
+			}
 			start := len(out) - length
 			if bytes.Equal(out[start:], delim) {
 				r.SkipRead(len(out))
 				return out[:start], true, nil
 			}
 		}
-		offset++
-		index++
 	}
-	return nil, false, nil
+}
+
+// ReadUvarint read unsigned varint
+func (r *Reader) ReadUvarint() (uint64, error) {
+	u, n, err := r.PeekUvarint()
+	if err != nil {
+		return 0, withOp(err, "ReadUvarint")
+	}
+	r.SkipRead(n)
+	return u, nil
+}
+
+// ReadVarint read zigzag encoded varint
+func (r *Reader) ReadVarint() (int64, error) {
+	i, n, err := r.PeekVarint()
+	if err != nil {
+		return 0, withOp(err, "ReadVarint")
+	}
+	r.SkipRead(n)
+	return i, nil
+}
+
+// ReadULEB128 read unsigned LEB128
+func (r *Reader) ReadULEB128() (uint64, error) {
+	u, n, err := r.PeekULEB128()
+	if err != nil {
+		return 0, withOp(err, "ReadULEB128")
+	}
+	r.SkipRead(n)
+	return u, nil
+}
+
+// ReadSLEB128 read signed LEB128
+func (r *Reader) ReadSLEB128() (int64, error) {
+	i, n, err := r.PeekSLEB128()
+	if err != nil {
+		return 0, withOp(err, "ReadSLEB128")
+	}
+	r.SkipRead(n)
+	return i, nil
+}
+
+// ReadProtoVarint read protobuf int32/int64 varint
+func (r *Reader) ReadProtoVarint() (int64, error) {
+	i, n, err := r.PeekProtoVarint()
+	if err != nil {
+		return 0, withOp(err, "ReadProtoVarint")
+	}
+	r.SkipRead(n)
+	return i, nil
+}
+
+// ReadLengthPrefixed read length-prefixed bytes, length larger than max is rejected before allocating
+func (r *Reader) ReadLengthPrefixed(prefix LengthPrefix, max int) ([]byte, error) {
+	b, n, err := r.PeekLengthPrefixed(prefix, max)
+	if err != nil {
+		return nil, withOp(err, "ReadLengthPrefixed")
+	}
+	r.SkipRead(n)
+	return b, nil
+}
+
+// ReadLengthPrefixedString read length-prefixed string, length larger than max is rejected before allocating
+func (r *Reader) ReadLengthPrefixedString(prefix LengthPrefix, max int) (string, error) {
+	b, err := r.ReadLengthPrefixed(prefix, max)
+	if err != nil {
+		return "", withOp(err, "ReadLengthPrefixedString")
+	}
+	return string(b), nil
 }
@@ -1,6 +1,11 @@
 package gobuf
 
 import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing/iotest"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -182,4 +187,57 @@ var _ = Describe("ReadWrite", func() {
 		Expect(ok).To(BeFalse())
 		Expect(string(read)).To(Equal(""))
 	})
+
+	It("should read until a delimiter longer than bytes read so far", func() {
+		b := New([]byte("abcd"))
+		read, ok, err := b.ReadUntil([]byte("xyz"), []byte("d"))
+		Expect(err).To(BeNil())
+		Expect(ok).To(BeTrue())
+		Expect(string(read)).To(Equal("abc"))
+	})
+
+	It("should read until from a stream", func() {
+		r := Read(iotest.OneByteReader(strings.NewReader("hello\r\nworld")), binary.BigEndian, NewListMemory(nil, FixedGrow(4)))
+		read, ok, err := r.ReadUntil([]byte("\r\n"))
+		Expect(err).To(BeNil())
+		Expect(ok).To(BeTrue())
+		Expect(string(read)).To(Equal("hello"))
+	})
+
+	It("should fail on short and negative lengths", func() {
+		b := New([]byte("abc"))
+		_, err := b.ReadBytes(4)
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(b.ReaderIndex()).To(Equal(0))
+		_, err = b.ReadString(-1)
+		Expect(err).To(MatchError(ErrOutOfRange))
+		_, err = b.PeekUint16(-1)
+		Expect(err).To(MatchError(ErrNegativeOffset))
+
+		r := Read(strings.NewReader("abc"), binary.BigEndian, NewListMemory(nil, FixedGrow(4)))
+		dst := make([]byte, 8)
+		n, err := r.Reader.Read(dst)
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(3))
+		Expect(r.ReaderIndex()).To(Equal(3))
+	})
+
+	It("should fail on huge lengths without allocating", func() {
+		b := New([]byte("abc"))
+		_, err := b.ReadBytes(1 << 62)
+		Expect(err).To(Equal(&Error{Op: "ReadBytes", Offset: 0, Want: 1 << 62, Available: 3, Err: ErrShortBuffer}))
+		_, err = b.PeekString(1<<62, 3)
+		Expect(err).To(MatchError(io.EOF))
+
+		// size of stream is unknown, bytes are peeked in chunks until it ends
+		data := strings.Repeat("x", 3*peekChunkSize+5)
+		r := Read(strings.NewReader(data), binary.BigEndian, NewListMemory(nil, FixedGrow(4096)))
+		_, err = r.ReadBytes(1 << 62)
+		Expect(err).To(Equal(&Error{Op: "ReadBytes", Offset: 0, Want: 1 << 62, Available: len(data), Err: ErrShortBuffer}))
+		s, err := r.ReadString(len(data))
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal(data))
+		_, err = r.ReadBytes(1 << 62)
+		Expect(err).To(MatchError(io.EOF))
+	})
 })
@@ -5,55 +5,166 @@ import (
 	"io"
 )
 
+// defaultReadAhead min number of bytes requested from underlying reader at once
+const defaultReadAhead = 4096
+
+// maxEmptyReads number of reads returning no data and no error tolerated before giving up
+const maxEmptyReads = 100
+
+// IOReader read only buffer backed by io.Reader, bytes are read ahead into memory and released once read past.
+// indexes are offsets in the stream, bytes before ReaderIndex may have been released
 type IOReader struct {
 	*Peeker
 	*Reader
 	reader io.Reader
-	read   int
 	mem    Memory
 	order  binary.ByteOrder
+
+	// stream offset of first byte in mem
+	base int
+	// number of bytes in mem
+	filled int
+	// read ahead size
+	chunk int
+	buf   []byte
+	// sticky error of underlying reader
+	err error
 }
 
-func Read(r io.Reader, order binary.ByteOrder, memory Memory) *IOReader {
+// Read create an IOReader, bytes already in memory are read first
+func Read(r io.Reader, order binary.ByteOrder, memory Memory, options ...ReaderOptionFunc) *IOReader {
 	rd := &IOReader{
 		reader: r,
 		mem:    memory,
-		read:   memory.Length(),
+		filled: memory.Length(),
 		order:  order,
+		chunk:  defaultReadAhead,
+	}
+	for _, option := range options {
+		option(rd)
 	}
 	rd.Peeker = NewPeeker(rd)
 	rd.Reader = NewRead(rd, rd.Peeker)
 	return rd
 }
 
+// Size stream offset of end of bytes read so far
+func (r *IOReader) Size() int {
+	return r.base + r.filled
+}
+
+func (r *IOReader) Order() binary.ByteOrder {
+	return r.order
+}
+
+// PeekAt implements Peekable, reads from underlying reader until dst can be filled.
+// returns io.EOF if no byte is available at given location, and less bytes with error if stream ends early
 func (r *IOReader) PeekAt(at int, dst []byte) (n int, err error) {
-	n = len(dst)
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if at < r.base {
+		return 0, newError("PeekAt", at, len(dst), 0, ErrDiscarded)
+	}
 
-	if at > r.read || (at+n) > r.read {
-		amount := at + n - r.read
-		b := make([]byte, amount)
-		_, err = r.reader.Read(b)
-		if err != nil {
-			return
+	for r.Size() < at+len(dst) && r.err == nil {
+		r.fill()
+	}
+
+	n = len(dst)
+	if available := r.Size() - at; available < n {
+		if available <= 0 {
+			return 0, r.err
 		}
-		r.read += amount
+		n = available
+		err = r.err
+	}
 
-		err = r.mem.Write(r.read-amount, b)
-		if err != nil {
-			return
+	if rerr := r.mem.Read(at-r.base, dst[:n]); rerr != nil {
+		return 0, newError("PeekAt", at, n, 0, rerr)
+	}
+	return n, err
+}
+
+// Read implements io.Reader, returns buffered bytes without waiting for dst to be filled
+func (r *IOReader) Read(dst []byte) (n int, err error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+
+	if r.Available() <= 0 {
+		if r.err != nil {
+			return 0, r.err
 		}
+		r.fill()
 	}
 
-	length := r.mem.Length()
 	n = len(dst)
-	if at+n > length {
-		n = r.mem.Length() - at
+	if available := r.Available(); available < n {
+		if available <= 0 {
+			return 0, r.err
+		}
+		n = available
 	}
 
-	err = r.mem.Read(at, dst[:n])
-	return
+	if err = r.mem.Read(r.ReaderIndex()-r.base, dst[:n]); err != nil {
+		return 0, newError("Read", r.ReaderIndex(), n, 0, err)
+	}
+	r.SkipRead(n)
+	return n, nil
 }
 
-func (r *IOReader) Order() binary.ByteOrder {
-	return r.order
+// fill read once from underlying reader into memory, errors are kept in r.err
+func (r *IOReader) fill() {
+	if err := r.release(); err != nil {
+		r.err = err
+		return
+	}
+
+	if r.buf == nil {
+		r.buf = make([]byte, r.chunk)
+	}
+
+	for i := 0; i < maxEmptyReads; i++ {
+		n, err := r.reader.Read(r.buf)
+		if n > 0 {
+			if werr := r.mem.Write(r.filled, r.buf[:n]); werr != nil {
+				r.err = werr
+				return
+			}
+			r.filled += n
+		}
+
+		if err != nil {
+			r.err = err
+			return
+		}
+		if n > 0 {
+			return
+		}
+	}
+	r.err = io.ErrNoProgress
+}
+
+// release drop bytes before reader index and reader mark from memory once there is at least a chunk of them
+func (r *IOReader) release() error {
+	n := r.ReaderIndex() - r.base
+	if r.Peeker.marked && r.Peeker.mark-r.base < n {
+		n = r.Peeker.mark - r.base
+	}
+	if n > r.filled {
+		n = r.filled
+	}
+	if n < r.chunk {
+		return nil
+	}
+
+	if d, ok := r.mem.(Discardable); ok {
+		d.Discard(n)
+	} else if err := moveMemory(r.mem, n, r.filled-n); err != nil {
+		return err
+	}
+	r.base += n
+	r.filled -= n
+	return nil
 }
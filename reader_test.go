@@ -1,8 +1,12 @@
 package gobuf
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
+	"io"
 	"strings"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -18,10 +22,91 @@ var _ = Describe("Reader", func() {
 		ExpectSizeError(4)(r.Read(b[:4]))
 		Expect(string(b[:4])).To(Equal("hell"))
 
-		ExpectSizeError(5)(r.Read(b[4:9]))
-		Expect(string(b[0:9])).To(Equal("hello wor"))
+		// buffered bytes are returned without waiting for more
+		ExpectSizeError(1)(r.Read(b[4:9]))
+		Expect(string(b[0:5])).To(Equal("hello"))
 
-		ExpectSizeError(2)(r.Read(b[9:]))
+		ExpectSizeError(6)(io.ReadFull(r, b[5:]))
 		Expect(string(b)).To(Equal("hello world"))
+
+		_, err := r.Read(b)
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	readers := map[string]func(io.Reader) io.Reader{
+		"OneByteReader": iotest.OneByteReader,
+		"HalfReader":    iotest.HalfReader,
+		"DataErrReader": iotest.DataErrReader,
+	}
+
+	for name, wrap := range readers {
+		wrap := wrap
+		It("should handle short reads with "+name, func() {
+			r := Read(wrap(bytes.NewReader(data)), binary.BigEndian, NewSliceMemory(nil, FixedGrow(64)), WithReadAhead(64))
+
+			u, err := r.PeekUint64()
+			Expect(err).To(BeNil())
+			Expect(u).To(Equal(binary.BigEndian.Uint64(data)))
+
+			for i := 0; i < len(data)-1; i += 2 {
+				v, err := r.ReadUint16()
+				Expect(err).To(BeNil())
+				Expect(v).To(Equal(binary.BigEndian.Uint16(data[i:])))
+			}
+			Expect(r.ReaderIndex()).To(Equal(len(data)))
+
+			_, err = r.ReadUint8()
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("should copy everything with "+name, func() {
+			r := Read(wrap(bytes.NewReader(data)), binary.BigEndian, NewListMemory(nil, FixedGrow(16)), WithReadAhead(16))
+			_, err := r.ReadUint8()
+			Expect(err).To(BeNil())
+			out, err := io.ReadAll(r)
+			Expect(err).To(BeNil())
+			Expect(out).To(Equal(data[1:]))
+		})
+	}
+
+	It("should report unexpected EOF", func() {
+		r := Read(strings.NewReader("abc"), binary.BigEndian, NewSliceMemory(nil, FixedGrow(8)))
+		_, err := r.ReadUint32()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(r.ReaderIndex()).To(Equal(0))
+
+		s, err := r.ReadString(3)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("abc"))
+		_, err = r.ReadUint32()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("should report errors of underlying reader", func() {
+		failure := errors.New("failure")
+		r := Read(io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(failure)), binary.BigEndian, NewSliceMemory(nil, FixedGrow(8)))
+		_, err := r.ReadUint32()
+		Expect(err).To(MatchError(failure))
+		v, err := r.ReadUint16()
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint16(0x6162)))
+	})
+
+	It("should release bytes read past", func() {
+		r := Read(bytes.NewReader(data), binary.BigEndian, NewSliceMemory(nil, FixedGrow(32)), WithReadAhead(32))
+		for i := 0; i < len(data); i += 100 {
+			_, err := r.ReadBytes(100)
+			Expect(err).To(BeNil())
+			Expect(r.mem.Length()).To(BeNumerically("<=", 256))
+		}
+		Expect(r.ReaderIndex()).To(Equal(len(data)))
+
+		_, err := r.PeekAt(0, make([]byte, 1))
+		Expect(err).To(MatchError(ErrDiscarded))
 	})
 })
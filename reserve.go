@@ -0,0 +1,112 @@
+package gobuf
+
+import (
+	"encoding/binary"
+)
+
+// Patchable a Writable which can fill reserved bytes after more data has been written
+type Patchable interface {
+	// ReserveAt mark n bytes at given location to be filled later, returns key used to fill them
+	ReserveAt(at, n int) (key int, err error)
+
+	// Fill fill reserved bytes identified by key with src
+	Fill(key int, src []byte) error
+}
+
+// Reservation handle to bytes skipped by Writer.Reserve, to be filled later
+type Reservation struct {
+	patchable Patchable
+	order     binary.ByteOrder
+	key       int
+	n         int
+	// scratch space for encoding values without allocation
+	scratch [binary.MaxVarintLen64]byte
+}
+
+// Reserve skip n bytes and return a handle to fill them later
+func (w *Writer) Reserve(n int) (*Reservation, error) {
+	if n < 0 {
+		return nil, newError("Reserve", w.index, n, 0, ErrOutOfRange)
+	}
+
+	p, ok := w.Writable.(Patchable)
+	if !ok {
+		return nil, ErrNotPatchable
+	}
+
+	key, err := p.ReserveAt(w.WriterIndex(), n)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.WriteBytes(make([]byte, n)); err != nil {
+		return nil, err
+	}
+
+	return &Reservation{
+		patchable: p,
+		order:     w.Order(),
+		key:       key,
+		n:         n,
+	}, nil
+}
+
+// Len number of bytes reserved
+func (r *Reservation) Len() int {
+	return r.n
+}
+
+// PutBytes fill reserved bytes with b, length of b must equal reserved length
+func (r *Reservation) PutBytes(b []byte) error {
+	if len(b) != r.n {
+		return ErrReservationSize
+	}
+	return r.patchable.Fill(r.key, b)
+}
+
+// PutUint8 fill reserved bytes with a uint8
+func (r *Reservation) PutUint8(val uint8) error {
+	r.scratch[0] = val
+	return r.PutBytes(r.scratch[:1])
+}
+
+// PutUint16 fill reserved bytes with a uint16
+func (r *Reservation) PutUint16(val uint16) error {
+	b := r.scratch[:2]
+	r.order.PutUint16(b, val)
+	return r.PutBytes(b)
+}
+
+// PutUint32 fill reserved bytes with a uint32
+func (r *Reservation) PutUint32(val uint32) error {
+	b := r.scratch[:4]
+	r.order.PutUint32(b, val)
+	return r.PutBytes(b)
+}
+
+// PutUint64 fill reserved bytes with a uint64
+func (r *Reservation) PutUint64(val uint64) error {
+	b := r.scratch[:8]
+	r.order.PutUint64(b, val)
+	return r.PutBytes(b)
+}
+
+// PutUvarint fill reserved bytes with an unsigned varint, padded with continuation bytes to take all reserved bytes
+func (r *Reservation) PutUvarint(val uint64) error {
+	if r.n == 0 || r.n > binary.MaxVarintLen64 {
+		return ErrReservationSize
+	}
+
+	b := r.scratch[:r.n]
+	last := len(b) - 1
+	for i := 0; i < last; i++ {
+		b[i] = byte(val) | 0x80
+		val >>= 7
+	}
+	if val >= 0x80 {
+		return ErrReservationSize
+	}
+	b[last] = byte(val)
+
+	return r.PutBytes(b)
+}
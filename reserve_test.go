@@ -0,0 +1,99 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reservation", func() {
+	It("should backpatch buffer", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)), WithBigEndian())
+
+		length, err := b.Reserve(4)
+		Expect(err).To(BeNil())
+		Expect(length.Len()).To(Equal(4))
+		Expect(b.WriterIndex()).To(Equal(4))
+
+		Expect(b.WriteString("hello world")).To(BeNil())
+		Expect(length.PutUint32(uint32(b.WriterIndex() - 4))).To(BeNil())
+		Expect(b.WriterIndex()).To(Equal(15))
+
+		u32, err := b.ReadUint32()
+		Expect(err).To(BeNil())
+		Expect(u32).To(Equal(uint32(11)))
+
+		s, err := b.ReadString(11)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("hello world"))
+	})
+
+	It("should pad uvarint to reserved size", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)))
+
+		r, err := b.Reserve(3)
+		Expect(err).To(BeNil())
+		Expect(b.WriteUint8(0xff)).To(BeNil())
+		Expect(r.PutUvarint(300)).To(BeNil())
+
+		u, n, err := b.PeekUvarint()
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint64(300)))
+		Expect(n).To(Equal(3))
+
+		Expect(r.PutUvarint(1 << 21)).To(Equal(ErrReservationSize))
+	})
+
+	It("should reject value of wrong size", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)))
+
+		r, err := b.Reserve(2)
+		Expect(err).To(BeNil())
+		Expect(r.PutUint32(1)).To(Equal(ErrReservationSize))
+		Expect(r.PutUint16(1)).To(BeNil())
+
+		_, err = b.Reserve(-1)
+		Expect(err).To(Equal(&Error{Op: "Reserve", Offset: 2, Want: -1, Err: ErrOutOfRange}))
+		Expect(b.WriterIndex()).To(Equal(2))
+	})
+
+	It("should fill without allocation", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(64)))
+		r, err := b.Reserve(8)
+		Expect(err).To(BeNil())
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = r.PutUint64(64)
+			_ = r.PutUvarint(1 << 40)
+		})
+		Expect(allocs).To(BeZero())
+		Expect(b.ReadUvarint()).To(Equal(uint64(1 << 40)))
+	})
+
+	It("should hold back io writer until every reservation is filled", func() {
+		out := bytes.NewBuffer(nil)
+		w := Write(out, binary.BigEndian)
+
+		Expect(w.WriteString("a")).To(BeNil())
+		outer, err := w.Reserve(2)
+		Expect(err).To(BeNil())
+		Expect(w.WriteString("b")).To(BeNil())
+		inner, err := w.Reserve(1)
+		Expect(err).To(BeNil())
+		Expect(w.WriteString("cd")).To(BeNil())
+		Expect(out.String()).To(Equal("a"))
+
+		Expect(inner.PutUint8(2)).To(BeNil())
+		Expect(out.String()).To(Equal("a"))
+		Expect(inner.PutUint8(2)).To(MatchError(ErrNoReservation))
+
+		Expect(outer.PutUint16(4)).To(BeNil())
+		Expect(out.Bytes()).To(Equal([]byte{'a', 0, 4, 'b', 2, 'c', 'd'}))
+		Expect(w.WriterIndex()).To(Equal(7))
+
+		Expect(w.WriteString("e")).To(BeNil())
+		Expect(out.String()).To(HaveSuffix("e"))
+	})
+})
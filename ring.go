@@ -0,0 +1,208 @@
+package gobuf
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Consumable memory which can reuse space of bytes once they are read
+type Consumable interface {
+	// Consume mark bytes before given location as read
+	Consume(at int)
+}
+
+// RingMemory fixed capacity memory wrapping around, logical locations keep increasing while storage is reused.
+// writing over bytes which are not consumed yet fails with ErrOutOfSpace, or blocks until they are if blocking
+type RingMemory struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  []byte
+	// logical location of storage start, moved by Discard
+	origin int
+	// bytes before read are consumed
+	read int
+	// end of bytes written
+	written  int
+	blocking bool
+	closed   bool
+}
+
+// NewRingMemory create a RingMemory of capacity bytes, it panics if capacity is not positive
+func NewRingMemory(capacity int) *RingMemory {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("gobuf: ring memory capacity must be positive, got %d", capacity))
+	}
+
+	m := &RingMemory{
+		buf: make([]byte, capacity),
+	}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// NewBlockingRingMemory create a RingMemory whose writes wait for space to be consumed by another goroutine,
+// it panics if capacity is not positive
+func NewBlockingRingMemory(capacity int) *RingMemory {
+	m := NewRingMemory(capacity)
+	m.blocking = true
+	return m
+}
+
+// Cap capacity of storage
+func (m *RingMemory) Cap() int {
+	return len(m.buf)
+}
+
+// Free number of bytes which can be written without overwriting unconsumed bytes
+func (m *RingMemory) Free() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.read + len(m.buf) - m.written
+}
+
+func (m *RingMemory) physical(at int) int {
+	return (at + m.origin) % len(m.buf)
+}
+
+// segments slices of storage covering n bytes at logical location
+func (m *RingMemory) segments(dst [][]byte, at, n int) [][]byte {
+	if n == 0 {
+		return dst
+	}
+	start := m.physical(at)
+	if end := start + n; end <= len(m.buf) {
+		return append(dst, m.buf[start:end])
+	}
+	return append(dst, m.buf[start:], m.buf[:start+n-len(m.buf)])
+}
+
+func (m *RingMemory) Write(at int, src []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if at < 0 {
+		return ErrNegativeOffset
+	}
+
+	end := at + len(src)
+	if len(src) > len(m.buf) {
+		return ErrOutOfSpace
+	}
+	if at < m.written-len(m.buf) {
+		return ErrDiscarded
+	}
+
+	for end > m.read+len(m.buf) {
+		if !m.blocking {
+			return ErrOutOfSpace
+		}
+		if m.closed {
+			return ErrClosed
+		}
+		m.cond.Wait()
+	}
+
+	var segments [2][]byte
+	wrote := 0
+	for _, segment := range m.segments(segments[:0], at, len(src)) {
+		wrote += copy(segment, src[wrote:])
+	}
+	if end > m.written {
+		m.written = end
+	}
+	return nil
+}
+
+func (m *RingMemory) Read(at int, dst []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if at < 0 || at < m.written-len(m.buf) {
+		return ErrDiscarded
+	}
+	if at+len(dst) > m.written {
+		return io.EOF
+	}
+
+	var segments [2][]byte
+	read := 0
+	for _, segment := range m.segments(segments[:0], at, len(dst)) {
+		read += copy(dst[read:], segment)
+	}
+	return nil
+}
+
+// Segments implements Segmented, at most two slices are returned as storage wraps around
+func (m *RingMemory) Segments(dst [][]byte, at, n int) [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.segments(dst, at, n)
+}
+
+// Bytes copy of unconsumed bytes
+func (m *RingMemory) Bytes() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]byte, 0, m.written-m.read)
+	for _, segment := range m.segments(nil, m.read, m.written-m.read) {
+		out = append(out, segment...)
+	}
+	return out
+}
+
+// Length logical end of bytes written
+func (m *RingMemory) Length() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.written
+}
+
+func (m *RingMemory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.origin = 0
+	m.read = 0
+	m.written = 0
+	m.cond.Broadcast()
+}
+
+// Consume implements Consumable, space of bytes before at can be written again
+func (m *RingMemory) Consume(at int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if at > m.written {
+		at = m.written
+	}
+	if at > m.read {
+		m.read = at
+		m.cond.Broadcast()
+	}
+}
+
+// Discard implements Discardable, logical locations are moved back by n without touching storage
+func (m *RingMemory) Discard(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.origin = (m.origin + n) % len(m.buf)
+	m.read -= n
+	if m.read < 0 {
+		m.read = 0
+	}
+	m.written -= n
+	if m.written < 0 {
+		m.written = 0
+	}
+}
+
+// Close wake up blocked writers, they fail with ErrClosed
+func (m *RingMemory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	m.cond.Broadcast()
+	return nil
+}
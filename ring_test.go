@@ -0,0 +1,113 @@
+package gobuf
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RingMemory", func() {
+	It("should reject capacity which is not positive", func() {
+		Expect(func() { NewRingMemory(0) }).To(PanicWith("gobuf: ring memory capacity must be positive, got 0"))
+		Expect(func() { NewBlockingRingMemory(-1) }).To(PanicWith("gobuf: ring memory capacity must be positive, got -1"))
+	})
+
+	It("should wrap around with Buffer", func() {
+		m := NewRingMemory(8)
+		b := New(nil, WithMemory(m), WithBigEndian())
+		Expect(b.Size()).To(Equal(0))
+
+		for i := 0; i < 100; i++ {
+			Expect(b.WriteUint32(uint32(i))).To(BeNil())
+			Expect(b.WriteUint16(uint16(i))).To(BeNil())
+			v, err := b.ReadUint32()
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(uint32(i)))
+			s, err := b.ReadUint16()
+			Expect(err).To(BeNil())
+			Expect(s).To(Equal(uint16(i)))
+		}
+		Expect(b.WriterIndex()).To(Equal(600))
+		Expect(b.ReaderIndex()).To(Equal(600))
+	})
+
+	It("should not overwrite unread bytes", func() {
+		m := NewRingMemory(8)
+		b := New(nil, WithMemory(m))
+		Expect(b.WriteString("abcdef")).To(BeNil())
+		Expect(b.WriteString("ghi")).To(MatchError(ErrOutOfSpace))
+		Expect(m.Free()).To(Equal(2))
+
+		b.SkipRead(2)
+		Expect(m.Free()).To(Equal(4))
+		b.MarkReader()
+		b.SkipRead(2)
+		Expect(m.Free()).To(Equal(4))
+		Expect(b.WriteString("ghij")).To(BeNil())
+		Expect(b.ResetToMark()).To(BeNil())
+
+		str, err := b.ReadString(8)
+		Expect(err).To(BeNil())
+		Expect(str).To(Equal("cdefghij"))
+		Expect(m.Bytes()).To(Equal([]byte("cdefghij")))
+
+		b.UnmarkReader()
+		b.SkipRead(0)
+		Expect(m.Free()).To(Equal(8))
+		Expect(b.WriteBytes(make([]byte, 9))).To(MatchError(ErrOutOfSpace))
+	})
+
+	It("should read contiguous segments", func() {
+		m := NewRingMemory(8)
+		b := New(nil, WithMemory(m))
+		Expect(b.WriteString("abcdef")).To(BeNil())
+		b.SkipRead(6)
+		Expect(b.WriteString("ghijk")).To(BeNil())
+
+		segments, err := b.ReadSegments(5)
+		Expect(err).To(BeNil())
+		Expect(segments).To(Equal([][]byte{[]byte("gh"), []byte("ijk")}))
+		Expect(b.Available()).To(Equal(0))
+
+		_, err = b.ReadSegments(1)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should keep locations when discarding", func() {
+		m := NewRingMemory(8)
+		b := New(nil, WithMemory(m))
+		Expect(b.WriteString("abcdef")).To(BeNil())
+		b.SkipRead(5)
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(0))
+		Expect(b.WriteString("ghijklm")).To(BeNil())
+		str, err := b.ReadString(8)
+		Expect(err).To(BeNil())
+		Expect(str).To(Equal("fghijklm"))
+	})
+
+	It("should block until space is consumed", func() {
+		m := NewBlockingRingMemory(4)
+		done := make(chan error, 1)
+		Expect(m.Write(0, []byte("abc"))).To(BeNil())
+		go func() {
+			done <- m.Write(3, []byte("def"))
+		}()
+
+		Consistently(done, 50*time.Millisecond).ShouldNot(Receive())
+		m.Consume(2)
+		Eventually(done).Should(Receive(BeNil()))
+
+		dst := make([]byte, 4)
+		Expect(m.Read(2, dst)).To(BeNil())
+		Expect(string(dst)).To(Equal("cdef"))
+
+		go func() {
+			done <- m.Write(6, []byte("g"))
+		}()
+		Consistently(done, 50*time.Millisecond).ShouldNot(Receive())
+		Expect(m.Close()).To(BeNil())
+		Eventually(done).Should(Receive(Equal(ErrClosed)))
+	})
+})
@@ -0,0 +1,279 @@
+package gobuf
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"sync/atomic"
+)
+
+// spscHeaderSize size of little endian uint32 record length written after the body
+const spscHeaderSize = 4
+
+// spscPad padding between indexes owned by different goroutines, so they do not share a cache line
+type spscPad [64 - 8]byte
+
+// SPSCQueue lock-free queue of length-prefixed records over a power of two ring of bytes.
+// it is safe for exactly one goroutine enqueuing while exactly one other goroutine dequeues
+type SPSCQueue struct {
+	_ spscPad
+	// head position of next record to dequeue, only stored by consumer
+	head uint64
+	_    spscPad
+	// tail position of next record to enqueue, only stored by producer
+	tail uint64
+	_    spscPad
+
+	buf   []byte
+	mask  uint64
+	order binary.ByteOrder
+
+	// producer side, reused for every record
+	writable spscWritable
+	writer   *Writer
+	// consumer side, reused for every record
+	peekable spscPeekable
+	peeker   *Peeker
+	reader   *Reader
+}
+
+// NewSPSCQueue create a queue holding capacity bytes including a 4 bytes header per record, capacity is rounded up to power of two
+func NewSPSCQueue(capacity int, order binary.ByteOrder) *SPSCQueue {
+	if capacity < spscHeaderSize {
+		capacity = spscHeaderSize
+	}
+	capacity = 1 << uint(bits.Len(uint(capacity-1)))
+
+	q := &SPSCQueue{
+		buf:   make([]byte, capacity),
+		mask:  uint64(capacity - 1),
+		order: order,
+	}
+	q.writable.q = q
+	q.writer = NewWriter(&q.writable)
+	q.peekable.q = q
+	q.peeker = NewPeeker(&q.peekable)
+	q.reader = NewRead(&q.peekable, q.peeker)
+	return q
+}
+
+// Cap capacity in bytes
+func (q *SPSCQueue) Cap() int {
+	return len(q.buf)
+}
+
+// Len number of bytes queued including headers
+func (q *SPSCQueue) Len() int {
+	return int(atomic.LoadUint64(&q.tail) - atomic.LoadUint64(&q.head))
+}
+
+// put copy src into ring at position
+func (q *SPSCQueue) put(pos uint64, src []byte) {
+	start := pos & q.mask
+	n := copy(q.buf[start:], src)
+	copy(q.buf, src[n:])
+}
+
+// get copy from ring at position into dst
+func (q *SPSCQueue) get(pos uint64, dst []byte) {
+	start := pos & q.mask
+	n := copy(dst, q.buf[start:])
+	copy(dst[n:], q.buf)
+}
+
+// EnqueueWith write a record through w, it is only published if fn returns nil.
+// fails with ErrOutOfSpace if record does not fit until more is dequeued, and ErrTooLarge if it never fits
+func (q *SPSCQueue) EnqueueWith(fn func(w *Writer) error) error {
+	tail := atomic.LoadUint64(&q.tail)
+	if err := q.enqueue(tail, fn); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&q.tail, tail+spscHeaderSize+uint64(q.writable.n))
+	return nil
+}
+
+// enqueue write a record at tail without publishing it
+func (q *SPSCQueue) enqueue(tail uint64, fn func(w *Writer) error) error {
+	free := len(q.buf) - int(tail-atomic.LoadUint64(&q.head))
+
+	q.writable.start = tail + spscHeaderSize
+	q.writable.n = 0
+	q.writable.limit = free - spscHeaderSize
+	q.writer.ResetWriter()
+	if err := fn(q.writer); err != nil {
+		return err
+	}
+	if q.writable.limit < 0 {
+		return ErrOutOfSpace
+	}
+
+	var header [spscHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(q.writable.n))
+	q.put(tail, header[:])
+	return nil
+}
+
+// Enqueue copy record into queue
+func (q *SPSCQueue) Enqueue(record []byte) error {
+	return q.EnqueueWith(func(w *Writer) error {
+		// bare errors, a producer spinning on a full queue should not allocate
+		_, err := w.Write(record)
+		return err
+	})
+}
+
+// EnqueueBatch copy records into queue and publish them at once, returns number of records enqueued
+func (q *SPSCQueue) EnqueueBatch(records [][]byte) (int, error) {
+	start := atomic.LoadUint64(&q.tail)
+	tail := start
+
+	var err error
+	count := 0
+	for _, record := range records {
+		record := record
+		if err = q.enqueue(tail, func(w *Writer) error {
+			_, err := w.Write(record)
+			return err
+		}); err != nil {
+			break
+		}
+		tail += spscHeaderSize + uint64(q.writable.n)
+		count++
+	}
+
+	if tail != start {
+		atomic.StoreUint64(&q.tail, tail)
+	}
+	return count, err
+}
+
+// DequeueWith read next record through r, it is only removed if fn returns nil. fails with ErrQueueEmpty if there is none
+func (q *SPSCQueue) DequeueWith(fn func(r *Reader) error) error {
+	head := atomic.LoadUint64(&q.head)
+	next, err := q.dequeue(head, atomic.LoadUint64(&q.tail), fn)
+	if err != nil {
+		return err
+	}
+	atomic.StoreUint64(&q.head, next)
+	return nil
+}
+
+// dequeue read record at head without removing it, returns position of next record
+func (q *SPSCQueue) dequeue(head, tail uint64, fn func(r *Reader) error) (uint64, error) {
+	if head == tail {
+		return head, ErrQueueEmpty
+	}
+
+	var header [spscHeaderSize]byte
+	q.get(head, header[:])
+	n := binary.LittleEndian.Uint32(header[:])
+
+	q.peekable.start = head + spscHeaderSize
+	q.peekable.n = int(n)
+	q.peeker.ResetReader()
+	if err := fn(q.reader); err != nil {
+		return head, err
+	}
+	return head + spscHeaderSize + uint64(n), nil
+}
+
+// Dequeue copy of next record, fails with ErrQueueEmpty if there is none
+func (q *SPSCQueue) Dequeue() ([]byte, error) {
+	var record []byte
+	err := q.DequeueWith(func(r *Reader) error {
+		record = make([]byte, r.Available())
+		_, err := r.Read(record)
+		return err
+	})
+	return record, err
+}
+
+// DequeueBatch read up to max records through r and remove them at once, returns number of records dequeued.
+// stops at first error of fn, records before it are removed
+func (q *SPSCQueue) DequeueBatch(max int, fn func(r *Reader) error) (int, error) {
+	start := atomic.LoadUint64(&q.head)
+	tail := atomic.LoadUint64(&q.tail)
+	head := start
+
+	var err error
+	count := 0
+	for ; count < max; count++ {
+		var next uint64
+		if next, err = q.dequeue(head, tail, fn); err != nil {
+			break
+		}
+		head = next
+	}
+	if err == ErrQueueEmpty && count > 0 {
+		err = nil
+	}
+
+	if head != start {
+		atomic.StoreUint64(&q.head, head)
+	}
+	return count, err
+}
+
+// spscWritable writes record data into ring after its header
+type spscWritable struct {
+	q     *SPSCQueue
+	start uint64
+	n     int
+	limit int
+}
+
+func (w *spscWritable) WriteSome(src []byte) (int, error) {
+	if w.n+len(src) > len(w.q.buf)-spscHeaderSize {
+		return 0, ErrTooLarge
+	}
+	if w.n+len(src) > w.limit {
+		return 0, ErrOutOfSpace
+	}
+
+	w.q.put(w.start+uint64(w.n), src)
+	w.n += len(src)
+	return len(src), nil
+}
+
+func (w *spscWritable) Order() binary.ByteOrder {
+	return w.q.order
+}
+
+// spscPeekable reads record data in ring
+type spscPeekable struct {
+	q     *SPSCQueue
+	start uint64
+	n     int
+}
+
+func (p *spscPeekable) PeekAt(at int, dst []byte) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if at < 0 {
+		return 0, ErrNegativeOffset
+	}
+	if at >= p.n {
+		return 0, io.EOF
+	}
+
+	n := len(dst)
+	if at+n > p.n {
+		n = p.n - at
+	}
+	p.q.get(p.start+uint64(at), dst[:n])
+	return n, nil
+}
+
+func (p *spscPeekable) Size() int {
+	return p.n
+}
+
+// peekSize implements sizedPeekable
+func (p *spscPeekable) peekSize() int {
+	return p.n
+}
+
+func (p *spscPeekable) Order() binary.ByteOrder {
+	return p.q.order
+}
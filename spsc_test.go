@@ -0,0 +1,147 @@
+package gobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SPSCQueue", func() {
+	It("should round capacity to power of two", func() {
+		Expect(NewSPSCQueue(0, binary.BigEndian).Cap()).To(Equal(4))
+		Expect(NewSPSCQueue(64, binary.BigEndian).Cap()).To(Equal(64))
+		Expect(NewSPSCQueue(65, binary.BigEndian).Cap()).To(Equal(128))
+	})
+
+	It("should enqueue and dequeue records", func() {
+		q := NewSPSCQueue(16, binary.BigEndian)
+		_, err := q.Dequeue()
+		Expect(err).To(Equal(ErrQueueEmpty))
+
+		Expect(q.Enqueue([]byte("abc"))).To(BeNil())
+		Expect(q.Enqueue(nil)).To(BeNil())
+		Expect(q.Len()).To(Equal(11))
+		Expect(q.Enqueue([]byte("defgh"))).To(Equal(ErrOutOfSpace))
+		Expect(q.Enqueue(make([]byte, 13))).To(Equal(ErrTooLarge))
+		Expect(q.Len()).To(Equal(11))
+
+		record, err := q.Dequeue()
+		Expect(err).To(BeNil())
+		Expect(record).To(Equal([]byte("abc")))
+		record, err = q.Dequeue()
+		Expect(err).To(BeNil())
+		Expect(record).To(Equal([]byte{}))
+		Expect(q.Len()).To(Equal(0))
+
+		// wraps around end of ring
+		Expect(q.Enqueue([]byte("defghijk"))).To(BeNil())
+		record, err = q.Dequeue()
+		Expect(err).To(BeNil())
+		Expect(record).To(Equal([]byte("defghijk")))
+	})
+
+	It("should write and read records with Writer and Reader", func() {
+		q := NewSPSCQueue(32, binary.BigEndian)
+		Expect(q.EnqueueWith(func(w *Writer) error {
+			if err := w.WriteUint16(0x0102); err != nil {
+				return err
+			}
+			return w.WriteUvarint(300)
+		})).To(BeNil())
+
+		fail := errors.New("fail")
+		Expect(q.EnqueueWith(func(w *Writer) error {
+			Expect(w.WriteUint32(1)).To(BeNil())
+			return fail
+		})).To(Equal(fail))
+		Expect(q.Len()).To(Equal(8))
+
+		Expect(q.DequeueWith(func(r *Reader) error {
+			Expect(r.Available()).To(Equal(4))
+			return fail
+		})).To(Equal(fail))
+		Expect(q.DequeueWith(func(r *Reader) error {
+			v, err := r.ReadUint16()
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(uint16(0x0102)))
+			u, err := r.ReadUvarint()
+			Expect(err).To(BeNil())
+			Expect(u).To(Equal(uint64(300)))
+			_, err = r.ReadByte()
+			return err
+		})).ToNot(BeNil())
+		Expect(q.Len()).To(Equal(8))
+	})
+
+	It("should enqueue and dequeue in batches", func() {
+		q := NewSPSCQueue(16, binary.BigEndian)
+		n, err := q.EnqueueBatch([][]byte{[]byte("a"), []byte("bc"), []byte("defgh")})
+		Expect(err).To(Equal(ErrOutOfSpace))
+		Expect(n).To(Equal(2))
+
+		var records []string
+		n, err = q.DequeueBatch(8, func(r *Reader) error {
+			s, err := r.ReadString(r.Available())
+			records = append(records, s)
+			return err
+		})
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(2))
+		Expect(records).To(Equal([]string{"a", "bc"}))
+
+		n, err = q.DequeueBatch(8, func(r *Reader) error { return nil })
+		Expect(err).To(Equal(ErrQueueEmpty))
+		Expect(n).To(Equal(0))
+	})
+
+	It("should pass records between goroutines", func() {
+		const count = 10000
+		q := NewSPSCQueue(256, binary.LittleEndian)
+
+		done := make(chan error, 1)
+		go func() {
+			for i := 0; i < count; {
+				batch := [][]byte{[]byte(fmt.Sprint(i)), []byte(fmt.Sprint(i + 1))}
+				if i+1 == count {
+					batch = batch[:1]
+				}
+				n, err := q.EnqueueBatch(batch)
+				if err != nil && err != ErrOutOfSpace {
+					done <- err
+					return
+				}
+				i += n
+				if n == 0 {
+					runtime.Gosched()
+				}
+			}
+			done <- nil
+		}()
+
+		for i := 0; i < count; {
+			n, err := q.DequeueBatch(3, func(r *Reader) error {
+				s, err := r.ReadString(r.Available())
+				if err != nil {
+					return err
+				}
+				if s != fmt.Sprint(i) {
+					return fmt.Errorf("expected %d, got %s", i, s)
+				}
+				i++
+				return nil
+			})
+			if err == ErrQueueEmpty {
+				runtime.Gosched()
+				continue
+			}
+			Expect(err).To(BeNil())
+			Expect(n).To(BeNumerically(">", 0))
+		}
+		Expect(<-done).To(BeNil())
+		Expect(q.Len()).To(Equal(0))
+	})
+})
@@ -0,0 +1,254 @@
+package gobuf
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SyncBuffer wrap a Buffer so one goroutine can write while another reads.
+// in pipe mode reads wait until enough bytes are written, the write side is closed or read deadline passes.
+// only Wait, ReadContext and WriteContext also stop waiting once a context is done.
+// each side is meant to be used by a single goroutine at a time
+type SyncBuffer struct {
+	*Peeker
+	*Reader
+	*Writer
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  *Buffer
+
+	pipe        bool
+	writeClosed bool
+	// readDeadline zero for no deadline, deadlineTimer wakes up waiting readers once it passes
+	readDeadline  time.Time
+	deadlineTimer *time.Timer
+}
+
+func newSyncBuffer(buf *Buffer, pipe bool) *SyncBuffer {
+	s := &SyncBuffer{
+		buf:  buf,
+		pipe: pipe,
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.Peeker = NewPeeker(s)
+	s.Reader = NewRead(s, s.Peeker)
+	s.Writer = NewWriter(s)
+	s.Peeker.index = buf.ReaderIndex() + buf.discarded
+	s.Writer.index = buf.WriterIndex() + buf.discarded
+	return s
+}
+
+// NewSyncBuffer wrap buf, reads do not wait for data. buf must not be used directly afterwards
+func NewSyncBuffer(buf *Buffer) *SyncBuffer {
+	return newSyncBuffer(buf, false)
+}
+
+// NewPipeBuffer wrap buf in pipe mode, reads wait until enough bytes are written. buf must not be used directly afterwards
+func NewPipeBuffer(buf *Buffer) *SyncBuffer {
+	return newSyncBuffer(buf, true)
+}
+
+// Size implements Readable, indexes of SyncBuffer are not moved back when read bytes are discarded
+func (s *SyncBuffer) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size()
+}
+
+func (s *SyncBuffer) size() int {
+	return s.buf.size + s.buf.discarded
+}
+
+func (s *SyncBuffer) Order() binary.ByteOrder {
+	return s.buf.Order()
+}
+
+// wait wait until n bytes are available at given location, write side is closed, read deadline passes or ctx is done
+func (s *SyncBuffer) wait(ctx context.Context, at, n int) error {
+	if !s.pipe {
+		return nil
+	}
+
+	defer s.watch(ctx)()
+	for s.size() < at+n && !s.writeClosed {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !s.readDeadline.IsZero() && !time.Now().Before(s.readDeadline) {
+			return os.ErrDeadlineExceeded
+		}
+		s.cond.Wait()
+	}
+	return nil
+}
+
+// watch wake up waiting goroutines once ctx is done, returned func must be called when done waiting
+func (s *SyncBuffer) watch(ctx context.Context) func() {
+	done := ctx.Done()
+	if done == nil {
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+	}
+}
+
+// Wait wait until n bytes are available to read, write side is closed, read deadline passes or ctx is done
+func (s *SyncBuffer) Wait(ctx context.Context, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.wait(ctx, s.ReaderIndex(), n); err != nil {
+		return err
+	}
+	if s.size() < s.ReaderIndex()+n {
+		return io.EOF
+	}
+	return nil
+}
+
+// PeekAt implements Peekable, waits for enough bytes in pipe mode
+func (s *SyncBuffer) PeekAt(at int, dst []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.wait(context.Background(), at, len(dst)); err != nil {
+		return 0, err
+	}
+	return s.buf.PeekAt(at-s.buf.discarded, dst)
+}
+
+// Read implements io.Reader, waits for at least one byte in pipe mode
+func (s *SyncBuffer) Read(dst []byte) (n int, err error) {
+	return s.ReadContext(context.Background(), dst)
+}
+
+// ReadContext read like Read, waiting stops once ctx is done
+func (s *SyncBuffer) ReadContext(ctx context.Context, dst []byte) (n int, err error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	if err = s.wait(ctx, s.ReaderIndex(), 1); err != nil {
+		s.mu.Unlock()
+		return 0, err
+	}
+	available := s.size() - s.ReaderIndex()
+	if available <= 0 {
+		s.mu.Unlock()
+		return 0, io.EOF
+	}
+	if available < len(dst) {
+		dst = dst[:available]
+	}
+	n, err = s.buf.PeekAt(s.ReaderIndex()-s.buf.discarded, dst)
+	s.mu.Unlock()
+
+	if n > 0 {
+		s.SkipRead(n)
+	}
+	return n, err
+}
+
+// readerSkipped forward reader index to wrapped buffer, so read bytes can be discarded or consumed
+func (s *SyncBuffer) readerSkipped(index int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Peeker.marked && s.Peeker.mark < index {
+		index = s.Peeker.mark
+	}
+	if n := index - s.buf.discarded - s.buf.ReaderIndex(); n > 0 {
+		s.buf.SkipRead(n)
+		// writers may wait for space of a blocking RingMemory
+		s.cond.Broadcast()
+	}
+}
+
+// WriteSome implements Writable, wakes up waiting readers
+func (s *SyncBuffer) WriteSome(src []byte) (n int, err error) {
+	return s.WriteContext(context.Background(), src)
+}
+
+// WriteContext write src, waiting for space of a blocking RingMemory stops once ctx is done
+func (s *SyncBuffer) WriteContext(ctx context.Context, src []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.writeClosed {
+		return 0, ErrWriterClosed
+	}
+
+	// wait for space here, a blocking RingMemory would otherwise block while holding the lock
+	if ring, ok := s.buf.mem.(*RingMemory); ok && ring.blocking && len(src) <= ring.Cap() {
+		stop := s.watch(ctx)
+		for ring.Free() < len(src) && !s.writeClosed {
+			if err = ctx.Err(); err != nil {
+				stop()
+				return 0, err
+			}
+			s.cond.Wait()
+		}
+		stop()
+		if s.writeClosed {
+			return 0, ErrWriterClosed
+		}
+	}
+
+	n, err = s.buf.Writer.Write(src)
+	if n > 0 {
+		s.cond.Broadcast()
+	}
+	return
+}
+
+// CloseWrite close write side, waiting readers get io.EOF once everything written is read
+func (s *SyncBuffer) CloseWrite() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeClosed = true
+	// waiting readers return now, deadline no longer matters
+	if s.deadlineTimer != nil {
+		s.deadlineTimer.Stop()
+		s.deadlineTimer = nil
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// SetReadDeadline waiting reads fail with os.ErrDeadlineExceeded once t passes, zero to wait forever
+func (s *SyncBuffer) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readDeadline = t
+	if s.deadlineTimer != nil {
+		s.deadlineTimer.Stop()
+		s.deadlineTimer = nil
+	}
+	if !t.IsZero() {
+		s.deadlineTimer = time.AfterFunc(time.Until(t), func() {
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		})
+	}
+	s.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,149 @@
+package gobuf
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SyncBuffer", func() {
+	It("should read and write from different goroutines", func() {
+		s := NewPipeBuffer(New(nil, WithAutoGrowMemory(FixedGrow(64)), WithAutoDiscard(32), WithBigEndian()))
+
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < 1000; i++ {
+				Expect(s.WriteUint16(uint16(i))).To(BeNil())
+				Expect(s.WriteLengthPrefixedString(PrefixUint8, "frame")).To(BeNil())
+			}
+			Expect(s.CloseWrite()).To(BeNil())
+		}()
+
+		for i := 0; i < 1000; i++ {
+			v, err := s.ReadUint16()
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(uint16(i)))
+			str, err := s.ReadLengthPrefixedString(PrefixUint8, 8)
+			Expect(err).To(BeNil())
+			Expect(str).To(Equal("frame"))
+		}
+		Expect(s.ReaderIndex()).To(Equal(8000))
+
+		_, err := s.ReadUint8()
+		Expect(err).To(MatchError(io.EOF))
+		Expect(s.WriteUint8(1)).To(MatchError(ErrWriterClosed))
+	})
+
+	It("should report unexpected EOF after close", func() {
+		s := NewPipeBuffer(New(nil, WithAutoGrowMemory(FixedGrow(64))))
+		Expect(s.WriteUint16(1)).To(BeNil())
+		Expect(s.CloseWrite()).To(BeNil())
+		_, err := s.ReadUint32()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	It("should work as io.Reader", func() {
+		s := NewPipeBuffer(New(nil, WithMemory(NewBlockingRingMemory(16))))
+		data := make([]byte, 10000)
+		for i := range data {
+			data[i] = byte(i)
+		}
+
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < len(data); i += 10 {
+				Expect(s.WriteBytes(data[i : i+10])).To(BeNil())
+			}
+			Expect(s.CloseWrite()).To(BeNil())
+		}()
+
+		out, err := io.ReadAll(s)
+		Expect(err).To(BeNil())
+		Expect(out).To(Equal(data))
+	})
+
+	It("should not wait without pipe mode", func() {
+		s := NewSyncBuffer(New(nil, WithAutoGrowMemory(FixedGrow(64)), WithBigEndian()))
+		_, err := s.ReadUint8()
+		Expect(err).To(MatchError(io.EOF))
+		Expect(s.WriteUint16(1)).To(BeNil())
+		v, err := s.ReadUint16()
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint16(1)))
+	})
+
+	It("should time out with read deadline", func() {
+		s := NewPipeBuffer(New(nil, WithAutoGrowMemory(FixedGrow(64))))
+		Expect(s.SetReadDeadline(time.Now().Add(20 * time.Millisecond))).To(BeNil())
+		start := time.Now()
+		_, err := s.ReadUint8()
+		Expect(err).To(MatchError(os.ErrDeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+
+		Expect(s.SetReadDeadline(time.Time{})).To(BeNil())
+		Expect(s.WriteUint8(7)).To(BeNil())
+		v, err := s.ReadUint8()
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint8(7)))
+	})
+
+	It("should wait with context", func() {
+		s := NewPipeBuffer(New(nil, WithAutoGrowMemory(FixedGrow(64))))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		Expect(s.Wait(ctx, 4)).To(Equal(context.DeadlineExceeded))
+
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(10 * time.Millisecond)
+			Expect(s.WriteUint32(1)).To(BeNil())
+		}()
+		Expect(s.Wait(context.Background(), 4)).To(BeNil())
+		v, err := s.ReadUint32()
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(binary.LittleEndian.Uint32([]byte{1, 0, 0, 0})))
+
+		Expect(s.CloseWrite()).To(BeNil())
+		Expect(s.Wait(context.Background(), 1)).To(Equal(io.EOF))
+	})
+
+	It("should read and write with context", func() {
+		s := NewPipeBuffer(New(nil, WithMemory(NewBlockingRingMemory(4))))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		n, err := s.ReadContext(ctx, make([]byte, 4))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(n).To(Equal(0))
+
+		n, err = s.WriteContext(context.Background(), []byte("full"))
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(4))
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		n, err = s.WriteContext(ctx, []byte("!"))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(n).To(Equal(0))
+
+		dst := make([]byte, 4)
+		n, err = s.ReadContext(context.Background(), dst)
+		Expect(err).To(BeNil())
+		Expect(string(dst[:n])).To(Equal("full"))
+	})
+
+	It("should stop deadline timer on close", func() {
+		s := NewPipeBuffer(New(nil, WithAutoGrowMemory(FixedGrow(64))))
+		Expect(s.SetReadDeadline(time.Now().Add(time.Hour))).To(BeNil())
+		timer := s.deadlineTimer
+		Expect(timer).NotTo(BeNil())
+		Expect(s.SetReadDeadline(time.Now().Add(time.Hour))).To(BeNil())
+		Expect(timer.Stop()).To(BeFalse())
+
+		Expect(s.CloseWrite()).To(BeNil())
+		Expect(s.deadlineTimer).To(BeNil())
+	})
+})
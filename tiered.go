@@ -0,0 +1,200 @@
+package gobuf
+
+import (
+	"io"
+	"os"
+)
+
+// MemoryTier storage currently used by TieredMemory
+type MemoryTier int
+
+const (
+	// SliceTier bytes are kept in memory
+	SliceTier MemoryTier = iota + 1
+	// FileTier bytes are kept in a temp file
+	FileTier
+)
+
+func (t MemoryTier) String() string {
+	switch t {
+	case SliceTier:
+		return "slice"
+	case FileTier:
+		return "file"
+	}
+	return "unknown"
+}
+
+// TieredMemory keep bytes in a SliceMemory until writing beyond threshold, then move them to a temp file
+type TieredMemory struct {
+	mem       *SliceMemory
+	grow      Grow
+	threshold int
+	// dir and pattern of temp file, see os.CreateTemp
+	dir     string
+	pattern string
+
+	file *os.File
+	// length size of file
+	length int
+	closed bool
+}
+
+// NewTieredMemory create a TieredMemory holding up to threshold bytes in memory, grow is capped at threshold.
+// temp file is created in dir, or default temp directory if empty
+func NewTieredMemory(threshold int, grow Grow, dir string) *TieredMemory {
+	m := &TieredMemory{
+		threshold: threshold,
+		dir:       dir,
+		pattern:   "gobuf-*",
+	}
+	m.grow = func(size, want int) int {
+		if grow != nil {
+			size = grow(size, want)
+		}
+		if size < want {
+			size = want
+		}
+		if size > threshold {
+			size = threshold
+		}
+		return size
+	}
+	m.mem = NewSliceMemory(nil, m.grow)
+	return m
+}
+
+// Tier storage currently used
+func (m *TieredMemory) Tier() MemoryTier {
+	if m.file != nil {
+		return FileTier
+	}
+	return SliceTier
+}
+
+// Name name of temp file, empty if bytes are kept in memory
+func (m *TieredMemory) Name() string {
+	if m.file == nil {
+		return ""
+	}
+	return m.file.Name()
+}
+
+// spill move bytes in memory to a new temp file
+func (m *TieredMemory) spill() error {
+	f, err := os.CreateTemp(m.dir, m.pattern)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(m.mem.buf); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	m.file = f
+	m.length = len(m.mem.buf)
+	m.mem.release()
+	return nil
+}
+
+func (m *TieredMemory) Write(at int, src []byte) error {
+	if m.closed {
+		return newError("Write", at, len(src), 0, ErrClosed)
+	}
+	if at < 0 {
+		return newError("Write", at, len(src), 0, ErrNegativeOffset)
+	}
+
+	end := at + len(src)
+	if m.file == nil {
+		if end <= m.threshold {
+			return m.mem.Write(at, src)
+		}
+		if err := m.spill(); err != nil {
+			return newError("Write", at, len(src), 0, err)
+		}
+	}
+
+	if _, err := m.file.WriteAt(src, int64(at)); err != nil {
+		return newError("Write", at, len(src), 0, err)
+	}
+	if end > m.length {
+		m.length = end
+	}
+	return nil
+}
+
+func (m *TieredMemory) Read(at int, dst []byte) error {
+	if m.closed {
+		return newError("Read", at, len(dst), 0, ErrClosed)
+	}
+	if at < 0 {
+		return newError("Read", at, len(dst), 0, ErrNegativeOffset)
+	}
+	if m.file == nil {
+		return m.mem.Read(at, dst)
+	}
+
+	if at+len(dst) > m.length {
+		return newError("Read", at, len(dst), remaining(m.length, at), io.EOF)
+	}
+	if _, err := m.file.ReadAt(dst, int64(at)); err != nil {
+		return newError("Read", at, len(dst), 0, err)
+	}
+	return nil
+}
+
+// Bytes copy of all bytes, bytes of file which can not be read are left zero
+func (m *TieredMemory) Bytes() []byte {
+	if m.file == nil {
+		return m.mem.Bytes()
+	}
+
+	out := make([]byte, m.length)
+	_, _ = m.file.ReadAt(out, 0)
+	return out
+}
+
+func (m *TieredMemory) Length() int {
+	if m.file == nil {
+		return m.mem.Length()
+	}
+	return m.length
+}
+
+// removeFile close and remove temp file
+func (m *TieredMemory) removeFile() error {
+	if m.file == nil {
+		return nil
+	}
+
+	err := m.file.Close()
+	if removeErr := os.Remove(m.file.Name()); err == nil {
+		err = removeErr
+	}
+	m.file = nil
+	m.length = 0
+	return err
+}
+
+// Reset remove temp file and keep bytes in memory again
+func (m *TieredMemory) Reset() {
+	if m.closed {
+		return
+	}
+
+	_ = m.removeFile()
+	m.mem.Reset()
+}
+
+// Close remove temp file and release memory, it must not be used afterwards
+func (m *TieredMemory) Close() error {
+	if m.closed {
+		return nil
+	}
+
+	m.closed = true
+	m.mem.release()
+	return m.removeFile()
+}
@@ -0,0 +1,113 @@
+package gobuf
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TieredMemory", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "gobuf-tiered")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(BeNil())
+	})
+
+	files := func() []os.DirEntry {
+		entries, err := os.ReadDir(dir)
+		Expect(err).To(BeNil())
+		return entries
+	}
+
+	It("should keep small data in memory", func() {
+		m := NewTieredMemory(64, FixedGrow(16), dir)
+		buf := New(nil, WithMemory(m))
+		Expect(buf.WriteString("hello")).To(BeNil())
+		Expect(m.Tier()).To(Equal(SliceTier))
+		Expect(m.Name()).To(Equal(""))
+		Expect(m.Length()).To(Equal(16))
+		Expect(files()).To(BeEmpty())
+
+		Expect(buf.WriteBytes(make([]byte, 59))).To(BeNil())
+		Expect(m.Tier()).To(Equal(SliceTier))
+		Expect(m.Length()).To(Equal(64))
+
+		s, err := buf.ReadString(5)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("hello"))
+	})
+
+	It("should move to file beyond threshold", func() {
+		m := NewTieredMemory(64, FixedGrow(16), dir)
+		buf := New(nil, WithMemory(m), WithBigEndian())
+		Expect(buf.WriteString("hello")).To(BeNil())
+
+		payload := bytes.Repeat([]byte("0123456789"), 100)
+		Expect(buf.WriteBytes(payload)).To(BeNil())
+		Expect(m.Tier()).To(Equal(FileTier))
+		Expect(m.Tier().String()).To(Equal("file"))
+		Expect(files()).To(HaveLen(1))
+		Expect(m.Length()).To(Equal(1005))
+
+		Expect(buf.WriteUint32(42)).To(BeNil())
+		s, err := buf.ReadString(5)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal("hello"))
+		b, err := buf.ReadBytes(len(payload))
+		Expect(err).To(BeNil())
+		Expect(b).To(Equal(payload))
+		v, err := buf.ReadUint32()
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint32(42)))
+
+		out := m.Bytes()
+		Expect(out).To(HaveLen(1009))
+		Expect(out[:5]).To(Equal([]byte("hello")))
+		Expect(m.Read(1000, make([]byte, 10))).To(Equal(&Error{Op: "Read", Offset: 1000, Want: 10, Available: 9, Err: io.EOF}))
+		Expect(m.Read(-1, make([]byte, 10))).To(Equal(&Error{Op: "Read", Offset: -1, Want: 10, Err: ErrNegativeOffset}))
+		Expect(m.Write(-1, []byte("x"))).To(Equal(&Error{Op: "Write", Offset: -1, Want: 1, Err: ErrNegativeOffset}))
+
+		// gaps are zero filled
+		Expect(m.Write(2000, []byte("x"))).To(BeNil())
+		Expect(m.Length()).To(Equal(2001))
+		gap := make([]byte, 4)
+		Expect(m.Read(1500, gap)).To(BeNil())
+		Expect(gap).To(Equal(make([]byte, 4)))
+	})
+
+	It("should remove file on reset and close", func() {
+		m := NewTieredMemory(8, nil, dir)
+		Expect(m.Write(0, make([]byte, 9))).To(BeNil())
+		Expect(m.Tier()).To(Equal(FileTier))
+		name := m.Name()
+		Expect(name).ToNot(Equal(""))
+
+		m.Reset()
+		Expect(m.Tier()).To(Equal(SliceTier))
+		Expect(files()).To(BeEmpty())
+		_, err := os.Stat(name)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		Expect(m.Write(0, []byte("abc"))).To(BeNil())
+		Expect(m.Write(6, []byte("def"))).To(BeNil())
+		Expect(m.Tier()).To(Equal(FileTier))
+		b := make([]byte, 9)
+		Expect(m.Read(0, b)).To(BeNil())
+		Expect(b).To(Equal([]byte("abc\x00\x00\x00def")))
+
+		Expect(m.Close()).To(BeNil())
+		Expect(files()).To(BeEmpty())
+		Expect(m.Write(0, []byte("a"))).To(Equal(&Error{Op: "Write", Offset: 0, Want: 1, Err: ErrClosed}))
+		Expect(m.Read(0, b)).To(MatchError(ErrClosed))
+		Expect(m.Close()).To(BeNil())
+	})
+})
@@ -0,0 +1,142 @@
+package gobuf
+
+import (
+	"errors"
+	"io"
+)
+
+// maxLEB128Len64 max length of a 64-bit value encoded as LEB128
+const maxLEB128Len64 = 10
+
+// UvarintSize number of bytes v takes as unsigned varint
+func UvarintSize(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+// VarintSize number of bytes v takes as zigzag encoded varint
+func VarintSize(v int64) int {
+	return UvarintSize(uint64(v<<1) ^ uint64(v>>63))
+}
+
+// putSLEB128 encode a signed LEB128 value into b, returns number of bytes written
+func putSLEB128(b []byte, v int64) int {
+	i := 0
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			b[i] = c
+			return i + 1
+		}
+		b[i] = c | 0x80
+		i++
+	}
+}
+
+// decodeZigZag decode a zigzag encoded value
+func decodeZigZag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// peekVarintByte peek i-th byte of a varint starts at offset, distinguishes empty and truncated input
+func (p *Peeker) peekVarintByte(op string, offset, i int) (byte, error) {
+	b, err := p.PeekByte(offset + i)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		if i == 0 {
+			return 0, newError(op, p.index+offset, 1, 0, io.EOF)
+		}
+		return 0, newError(op, p.index+offset, i+1, i, ErrShortBuffer)
+	}
+	if err != nil {
+		return 0, newError(op, p.index+offset, i+1, i, err)
+	}
+	return b, nil
+}
+
+// varintOverflow error of a varint starts at offset which overflows at i-th byte
+func (p *Peeker) varintOverflow(op string, offset, i int) error {
+	return newError(op, p.index+offset, i+1, i+1, ErrVarintOverflow)
+}
+
+// peekULEB128 peek an unsigned LEB128 value of at most limit bytes, 0 means unlimited
+func (p *Peeker) peekULEB128(op string, limit int, offset []int) (uint64, int, error) {
+	o := peekOffset(offset)
+
+	var x uint64
+	var s uint
+	for i := 0; ; i++ {
+		if limit > 0 && i == limit {
+			return 0, 0, newError(op, p.index+o, i+1, i, ErrVarintOverflow)
+		}
+
+		b, err := p.peekVarintByte(op, o, i)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		low := uint64(b & 0x7f)
+		switch {
+		case s > 63:
+			// only zero padding allowed beyond 64 bits
+			if low != 0 {
+				return 0, 0, p.varintOverflow(op, o, i)
+			}
+		case s == 63 && low > 1:
+			return 0, 0, p.varintOverflow(op, o, i)
+		default:
+			x |= low << s
+		}
+
+		if b < 0x80 {
+			return x, i + 1, nil
+		}
+		if s < 64 {
+			s += 7
+		}
+	}
+}
+
+// peekSLEB128 peek a signed LEB128 value
+func (p *Peeker) peekSLEB128(op string, offset []int) (int64, int, error) {
+	o := peekOffset(offset)
+
+	var x int64
+	var s uint
+	for i := 0; ; i++ {
+		b, err := p.peekVarintByte(op, o, i)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		low := int64(b & 0x7f)
+		switch {
+		case s > 63:
+			// only sign extension allowed beyond 64 bits
+			if (x < 0 && low != 0x7f) || (x >= 0 && low != 0) {
+				return 0, 0, p.varintOverflow(op, o, i)
+			}
+		case s == 63:
+			if low != 0 && low != 0x7f {
+				return 0, 0, p.varintOverflow(op, o, i)
+			}
+			x |= low << s
+		default:
+			x |= low << s
+		}
+
+		if b < 0x80 {
+			if s < 63 && b&0x40 != 0 {
+				x |= -1 << (s + 7)
+			}
+			return x, i + 1, nil
+		}
+		if s < 64 {
+			s += 7
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package gobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Varint", func() {
+	It("should write/read uvarint compatible with encoding/binary", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		values := []uint64{0, 1, 127, 128, 300, math.MaxUint32, math.MaxUint64}
+		for _, v := range values {
+			Expect(b.WriteUvarint(v)).To(BeNil())
+		}
+
+		raw := b.Bytes()[:b.Size()]
+		for _, v := range values {
+			u, n := binary.Uvarint(raw)
+			Expect(n).To(BeNumerically(">", 0))
+			Expect(u).To(Equal(v))
+			raw = raw[n:]
+		}
+
+		u, n, err := b.PeekUvarint()
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint64(0)))
+		Expect(n).To(Equal(1))
+
+		u, n, err = b.PeekUvarint(3)
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint64(128)))
+		Expect(n).To(Equal(2))
+
+		for _, v := range values {
+			u, err = b.ReadUvarint()
+			Expect(err).To(BeNil())
+			Expect(u).To(Equal(v))
+		}
+		Expect(b.Available()).To(Equal(0))
+	})
+
+	It("should write/read zigzag varint", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		values := []int64{0, -1, 1, -64, 64, math.MinInt64, math.MaxInt64}
+		for _, v := range values {
+			Expect(b.WriteVarint(v)).To(BeNil())
+		}
+
+		i, n, err := b.PeekVarint(1)
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-1)))
+		Expect(n).To(Equal(1))
+
+		for _, v := range values {
+			i, err = b.ReadVarint()
+			Expect(err).To(BeNil())
+			Expect(i).To(Equal(v))
+		}
+	})
+
+	It("should write/read LEB128", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(b.WriteULEB128(624485)).To(BeNil())
+		Expect(b.WriteSLEB128(-123456)).To(BeNil())
+		Expect(b.WriteSLEB128(math.MinInt64)).To(BeNil())
+		Expect(b.WriteSLEB128(63)).To(BeNil())
+		Expect(b.WriteSLEB128(64)).To(BeNil())
+		Expect(b.Bytes()[:8]).To(Equal([]byte{0xe5, 0x8e, 0x26, 0xc0, 0xbb, 0x78, 0x80, 0x80}))
+
+		u, err := b.ReadULEB128()
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint64(624485)))
+
+		i, n, err := b.PeekSLEB128()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-123456)))
+		Expect(n).To(Equal(3))
+
+		for _, v := range []int64{-123456, math.MinInt64, 63, 64} {
+			i, err = b.ReadSLEB128()
+			Expect(err).To(BeNil())
+			Expect(i).To(Equal(v))
+		}
+	})
+
+	It("should accept padded ULEB128", func() {
+		b := New([]byte{0x81, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00})
+		u, n, err := b.PeekULEB128()
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint64(1)))
+		Expect(n).To(Equal(12))
+
+		_, _, err = b.PeekUvarint()
+		Expect(err).To(MatchError(ErrVarintOverflow))
+	})
+
+	It("should write/read protobuf varint", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(32)))
+		Expect(b.WriteProtoVarint(-1)).To(BeNil())
+		Expect(b.WriteProtoVarint(150)).To(BeNil())
+		Expect(b.Size()).To(Equal(12))
+		Expect(b.Bytes()[10:12]).To(Equal([]byte{0x96, 0x01}))
+
+		i, n, err := b.PeekProtoVarint()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-1)))
+		Expect(n).To(Equal(10))
+
+		i, err = b.ReadProtoVarint()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-1)))
+
+		i, err = b.ReadProtoVarint()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(150)))
+	})
+
+	It("should report overflow", func() {
+		b := New([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02})
+		_, err := b.ReadUvarint()
+		Expect(err).To(MatchError(ErrVarintOverflow))
+		Expect(b.ReaderIndex()).To(Equal(0))
+
+		b = New([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x80, 0x00})
+		_, err = b.ReadUvarint()
+		Expect(err).To(MatchError(ErrVarintOverflow))
+
+		b = New([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02})
+		_, err = b.ReadULEB128()
+		Expect(err).To(MatchError(ErrVarintOverflow))
+
+		b = New([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01})
+		_, err = b.ReadSLEB128()
+		Expect(err).To(MatchError(ErrVarintOverflow))
+	})
+
+	It("should report truncation", func() {
+		b := New([]byte{0x80, 0x80})
+		_, err := b.ReadUvarint()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(b.ReaderIndex()).To(Equal(0))
+
+		_, err = b.ReadSLEB128()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+		b = New(nil)
+		_, err = b.ReadVarint()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("should write to IOWriter and read from IOReader", func() {
+		out := bytes.NewBuffer(nil)
+		w := Write(out, binary.LittleEndian)
+		Expect(w.WriteUvarint(300)).To(BeNil())
+		Expect(w.WriteVarint(-300)).To(BeNil())
+		Expect(w.WriteSLEB128(-300)).To(BeNil())
+		Expect(w.WriteProtoVarint(-300)).To(BeNil())
+		Expect(w.WriterIndex()).To(Equal(16))
+
+		r := Read(out, binary.LittleEndian, NewSliceMemory(nil, FixedGrow(8)))
+		u, err := r.ReadUvarint()
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal(uint64(300)))
+
+		i, err := r.ReadVarint()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-300)))
+
+		i, err = r.ReadSLEB128()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-300)))
+
+		i, err = r.ReadProtoVarint()
+		Expect(err).To(BeNil())
+		Expect(i).To(Equal(int64(-300)))
+
+		_, err = r.ReadUvarint()
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
+
+var _ = Describe("VarintSize", func() {
+	It("should match encoded size", func() {
+		b := make([]byte, binary.MaxVarintLen64)
+		for _, v := range []int64{0, 1, -1, 63, -64, 64, 1 << 20, math.MinInt64, math.MaxInt64} {
+			Expect(VarintSize(v)).To(Equal(binary.PutVarint(b, v)))
+			Expect(UvarintSize(uint64(v))).To(Equal(binary.PutUvarint(b, uint64(v))))
+		}
+	})
+})
@@ -0,0 +1,163 @@
+package gobuf
+
+import "io"
+
+// windowMemory bounded window of another Memory, used by views
+type windowMemory struct {
+	mem    Memory
+	offset int
+	length int
+	// buffer owning mem, offset is moved back by bytes it discarded since base. nil if mem is not owned by a buffer
+	parent *Buffer
+	base   int
+}
+
+// start location of window in mem
+func (m *windowMemory) start() int {
+	if m.parent == nil {
+		return m.offset
+	}
+	return m.offset - (m.parent.discarded - m.base)
+}
+
+func (m *windowMemory) Write(at int, src []byte) error {
+	if at < 0 {
+		return newError("Write", at, len(src), 0, ErrNegativeOffset)
+	}
+	if at+len(src) > m.length {
+		return newError("Write", at, len(src), remaining(m.length, at), ErrOutOfSpace)
+	}
+	return m.mem.Write(m.start()+at, src)
+}
+
+func (m *windowMemory) Read(at int, dst []byte) error {
+	if at < 0 {
+		return newError("Read", at, len(dst), 0, ErrNegativeOffset)
+	}
+	if at+len(dst) > m.length {
+		return newError("Read", at, len(dst), remaining(m.length, at), io.EOF)
+	}
+	return m.mem.Read(m.start()+at, dst)
+}
+
+func (m *windowMemory) Bytes() []byte {
+	out := make([]byte, m.length)
+	if err := m.mem.Read(m.start(), out); err != nil {
+		return nil
+	}
+	return out
+}
+
+func (m *windowMemory) Length() int {
+	return m.length
+}
+
+// Reset does nothing, memory is owned by parent
+func (m *windowMemory) Reset() {}
+
+// Discard implements Discardable, window is narrowed and shared memory is left untouched
+func (m *windowMemory) Discard(n int) {
+	if n > m.length {
+		n = m.length
+	}
+	m.offset += n
+	m.length -= n
+}
+
+// release implements releasable, parent is no longer held back from discarding bytes of window
+func (m *windowMemory) release() {
+	if m.parent == nil {
+		return
+	}
+	m.parent.unpin(m)
+	m.parent = nil
+}
+
+// pin window over buf, bytes from the start of window on are not discarded until it is released
+func (buf *Buffer) pin(offset, length int) *windowMemory {
+	m := &windowMemory{
+		mem:    buf.mem,
+		offset: offset,
+		length: length,
+		parent: buf,
+		base:   buf.discarded,
+	}
+	buf.views = append(buf.views, m)
+	return m
+}
+
+// unpin remove window of a released view
+func (buf *Buffer) unpin(m *windowMemory) {
+	for i, view := range buf.views {
+		if view == m {
+			buf.views = append(buf.views[:i], buf.views[i+1:]...)
+			return
+		}
+	}
+}
+
+// Segments implements Segmented if window is over Segmented memory
+func (m *windowMemory) Segments(dst [][]byte, at, n int) [][]byte {
+	if s, ok := m.mem.(Segmented); ok {
+		return s.Segments(dst, m.start()+at, n)
+	}
+
+	b := make([]byte, n)
+	if err := m.mem.Read(m.start()+at, b); err != nil {
+		return dst
+	}
+	return append(dst, b)
+}
+
+// view create a buffer over length bytes of memory starts at offset
+func (buf *Buffer) view(offset, length int) (*Buffer, error) {
+	if offset < 0 {
+		return nil, newError("Slice", offset, length, 0, ErrNegativeOffset)
+	}
+	if length < 0 || offset+length > buf.size {
+		return nil, newError("Slice", offset, length, remaining(buf.size, offset), ErrOutOfRange)
+	}
+
+	v := &Buffer{
+		mem:      buf.pin(offset, length),
+		size:     length,
+		order:    buf.order,
+		readOnly: buf.readOnly,
+	}
+	v.Peeker = NewPeeker(v)
+	v.Reader = NewRead(v, v.Peeker)
+	v.Writer = NewWriter(v)
+	v.Writer.index = length
+	return v, nil
+}
+
+// Slice view of length bytes starts at offset, sharing memory without copying.
+// reader index of view is 0 and writer index is length, neither can go beyond the window.
+// bytes of buffer from offset on are not discarded until view is released
+func (buf *Buffer) Slice(offset, length int) (*Buffer, error) {
+	return buf.view(offset, length)
+}
+
+// Duplicate view of all bytes sharing memory without copying, with a copy of both indexes.
+// no bytes of buffer are discarded until view is released
+func (buf *Buffer) Duplicate() *Buffer {
+	v, _ := buf.view(0, buf.size)
+	v.Peeker.index = buf.ReaderIndex()
+	v.Writer.index = buf.WriterIndex()
+	return v
+}
+
+// ReadSlice view of next n bytes sharing memory without copying, reader index is advanced.
+// bytes of buffer from the start of view on are not discarded until view is released
+func (buf *Buffer) ReadSlice(n int) (*Buffer, error) {
+	if err := buf.checkAvailable("ReadSlice", n); err != nil {
+		return nil, err
+	}
+
+	v, err := buf.view(buf.ReaderIndex(), n)
+	if err != nil {
+		return nil, withOp(err, "ReadSlice")
+	}
+	buf.SkipRead(n)
+	return v, nil
+}
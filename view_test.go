@@ -0,0 +1,148 @@
+package gobuf
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("View", func() {
+	It("should slice without copying", func() {
+		for _, option := range []OptionFunc{WithAutoGrowMemory(FixedGrow(4)), WithLinkedListMemory(FixedGrow(4))} {
+			b := New(nil, option)
+			Expect(b.WriteString("header|body|trailer")).To(BeNil())
+
+			s, err := b.Slice(7, 4)
+			Expect(err).To(BeNil())
+			Expect(s.Size()).To(Equal(4))
+			Expect(s.ReaderIndex()).To(Equal(0))
+			Expect(s.WriterIndex()).To(Equal(4))
+			Expect(s.Bytes()).To(Equal([]byte("body")))
+
+			// writes are bounded by window and visible to parent
+			Expect(s.WriteUint8('x')).To(MatchError(ErrOutOfSpace))
+			_, err = s.SeekWriter(0, io.SeekStart)
+			Expect(err).To(BeNil())
+			Expect(s.WriteString("BODY")).To(BeNil())
+			Expect(s.WriteUint8('x')).To(MatchError(ErrOutOfSpace))
+			Expect(b.Bytes()[:b.Size()]).To(Equal([]byte("header|BODY|trailer")))
+
+			// reads are bounded by window
+			str, err := s.ReadString(4)
+			Expect(err).To(BeNil())
+			Expect(str).To(Equal("BODY"))
+			_, err = s.ReadUint8()
+			Expect(err).To(MatchError(io.EOF))
+			_, err = s.PeekUint8(-5)
+			Expect(err).NotTo(BeNil())
+		}
+	})
+
+	It("should reject slices out of range", func() {
+		b := New([]byte("hello"))
+		_, err := b.Slice(-1, 2)
+		Expect(err).To(MatchError(ErrNegativeOffset))
+		_, err = b.Slice(3, 3)
+		Expect(err).To(MatchError(ErrOutOfRange))
+		s, err := b.Slice(5, 0)
+		Expect(err).To(BeNil())
+		Expect(s.Size()).To(Equal(0))
+
+		s, err = b.Slice(1, 3)
+		Expect(err).To(BeNil())
+		Expect(s.mem.Write(2, []byte("xy"))).To(Equal(&Error{Op: "Write", Offset: 2, Want: 2, Available: 1, Err: ErrOutOfSpace}))
+		Expect(s.mem.Write(-1, []byte("x"))).To(Equal(&Error{Op: "Write", Offset: -1, Want: 1, Err: ErrNegativeOffset}))
+		Expect(s.mem.Read(1, make([]byte, 4))).To(Equal(&Error{Op: "Read", Offset: 1, Want: 4, Available: 2, Err: io.EOF}))
+		Expect(s.mem.Read(-1, make([]byte, 1))).To(Equal(&Error{Op: "Read", Offset: -1, Want: 1, Err: ErrNegativeOffset}))
+	})
+
+	It("should duplicate with independent indexes", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(4)), WithBigEndian())
+		Expect(b.WriteUint32(0x01020304)).To(BeNil())
+		b.SkipRead(1)
+
+		d := b.Duplicate()
+		Expect(d.ReaderIndex()).To(Equal(1))
+		Expect(d.WriterIndex()).To(Equal(4))
+		Expect(d.Order()).To(Equal(b.Order()))
+
+		v, err := d.ReadUint8()
+		Expect(err).To(BeNil())
+		Expect(v).To(Equal(uint8(2)))
+		Expect(b.ReaderIndex()).To(Equal(1))
+
+		// discarding a view narrows its window only
+		Expect(d.DiscardReadBytes()).To(BeNil())
+		Expect(d.Size()).To(Equal(2))
+		Expect(b.Bytes()[:4]).To(Equal([]byte{1, 2, 3, 4}))
+	})
+
+	It("should read slice", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(4)), WithBigEndian())
+		Expect(b.WriteUint16(3)).To(BeNil())
+		Expect(b.WriteString("abcde")).To(BeNil())
+
+		n, err := b.ReadUint16()
+		Expect(err).To(BeNil())
+		body, err := b.ReadSlice(int(n))
+		Expect(err).To(BeNil())
+		Expect(b.ReaderIndex()).To(Equal(5))
+		str, err := body.ReadString(body.Available())
+		Expect(err).To(BeNil())
+		Expect(str).To(Equal("abc"))
+
+		_, err = b.ReadSlice(3)
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(b.ReaderIndex()).To(Equal(5))
+		_, err = b.ReadSlice(2)
+		Expect(err).To(BeNil())
+		_, err = b.ReadSlice(1)
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("should keep bytes of views when parent discards", func() {
+		for _, option := range []OptionFunc{WithAutoGrowMemory(FixedGrow(8)), WithLinkedListMemory(FixedGrow(4))} {
+			b := New(nil, option, WithAutoDiscard(4))
+			Expect(b.WriteString("headbody")).To(BeNil())
+			_, err := b.ReadString(4)
+			Expect(err).To(BeNil())
+			body, err := b.ReadSlice(4)
+			Expect(err).To(BeNil())
+
+			// auto discard drops header only, body is held back by view
+			Expect(b.WriteString("next")).To(BeNil())
+			Expect(b.ReaderIndex()).To(Equal(4))
+			Expect(body.PeekString(4)).To(Equal("body"))
+
+			dup := b.Duplicate()
+			Expect(b.DiscardReadBytes()).To(BeNil())
+			Expect(b.ReaderIndex()).To(Equal(4))
+			s, err := dup.ReadString(4)
+			Expect(err).To(BeNil())
+			Expect(s).To(Equal("next"))
+
+			// released views no longer hold bytes back
+			body.Release()
+			dup.Release()
+			Expect(b.DiscardReadBytes()).To(BeNil())
+			Expect(b.ReaderIndex()).To(Equal(0))
+			Expect(b.PeekString(4)).To(Equal("next"))
+		}
+	})
+
+	It("should keep bytes of composite components when buffer discards", func() {
+		b := New(nil, WithAutoGrowMemory(FixedGrow(8)))
+		Expect(b.WriteString("headbody")).To(BeNil())
+		b.SkipRead(4)
+		c := NewCompositeBuffer(b.Order())
+		Expect(c.AddBuffer(b)).To(BeNil())
+		b.SkipRead(4)
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(c.PeekString(4)).To(Equal("body"))
+
+		Expect(c.RemoveComponent(0)).To(BeNil())
+		Expect(b.DiscardReadBytes()).To(BeNil())
+		Expect(b.Size()).To(Equal(0))
+	})
+})
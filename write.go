@@ -13,6 +13,11 @@ type Writable interface {
 type Writer struct {
 	Writable
 	index int
+	// writer index saved by MarkWriter
+	mark   int
+	marked bool
+	// scratch space for encoding numbers without allocation
+	scratch [binary.MaxVarintLen64]byte
 }
 
 func NewWriter(w Writable) *Writer {
@@ -27,101 +32,145 @@ func (w *Writer) WriterIndex() int {
 
 func (w *Writer) ResetWriter() {
 	w.index = 0
+	w.marked = false
 }
 
 func (w *Writer) Write(src []byte) (n int, err error) {
-	size := w.WriterIndex() + len(src)
 	n, err = w.WriteSome(src)
-	if err != nil {
-		return
-	}
-	w.index = size
+	w.index += n
 	return
 }
 
 // WriteBool write a bool value as byte
 func (w *Writer) WriteBool(val bool) error {
 	if val {
-		return w.WriteByte(byte(1))
+		return withOp(w.WriteByte(byte(1)), "WriteBool")
 	}
-	return w.WriteByte(byte(0))
+	return withOp(w.WriteByte(byte(0)), "WriteBool")
 }
 
 // WriteByte write a byte into buffer
 func (w *Writer) WriteByte(b byte) error {
-	_, err := w.Write([]byte{b})
-	return err
+	w.scratch[0] = b
+	return w.write("WriteByte", w.scratch[:1])
 }
 
 // WriteBytes write bytes into buffer
 func (w *Writer) WriteBytes(b []byte) error {
-	_, err := w.Write(b)
-	return err
+	return w.write("WriteBytes", b)
 }
 
 // WriteString write a string into buffer
 func (w *Writer) WriteString(s string) error {
-	return w.WriteBytes([]byte(s))
+	return w.write("WriteString", []byte(s))
+}
+
+// write write b, errors are reported as operation op at writer index before writing
+func (w *Writer) write(op string, b []byte) error {
+	at := w.index
+	n, err := w.Write(b)
+	if err != nil {
+		return newError(op, at, len(b), n, err)
+	}
+	return nil
 }
 
 // WriteUint8 write a uint8 into buffer
 func (w *Writer) WriteUint8(val uint8) error {
-	return w.WriteByte(val)
+	return withOp(w.WriteByte(val), "WriteUint8")
 }
 
 // WriteUint16 write a uint16 into buffer
 func (w *Writer) WriteUint16(val uint16) error {
-	b := make([]byte, 2)
-	w.Order().PutUint16(b, val)
-	return w.WriteBytes(b)
+	w.Order().PutUint16(w.scratch[:2], val)
+	return w.write("WriteUint16", w.scratch[:2])
 }
 
 // WriteUint32 write a uint32 into buffer
 func (w *Writer) WriteUint32(val uint32) error {
-	b := make([]byte, 4)
-	w.Order().PutUint32(b, val)
-	return w.WriteBytes(b)
+	w.Order().PutUint32(w.scratch[:4], val)
+	return w.write("WriteUint32", w.scratch[:4])
 }
 
 // WriteUint64 write a uint64 into buffer
 func (w *Writer) WriteUint64(val uint64) error {
-	b := make([]byte, 8)
-	w.Order().PutUint64(b, val)
-	return w.WriteBytes(b)
+	w.Order().PutUint64(w.scratch[:8], val)
+	return w.write("WriteUint64", w.scratch[:8])
 }
 
 // WriteUint8 write a int8 into buffer
 func (w *Writer) WriteInt8(val int8) error {
-	return w.WriteByte(byte(val))
+	return withOp(w.WriteByte(byte(val)), "WriteInt8")
 }
 
 // WriteInt16 write a int16 into buffer
 func (w *Writer) WriteInt16(val int16) error {
-	b := make([]byte, 2)
-	w.Order().PutUint16(b, uint16(val))
-	return w.WriteBytes(b)
+	w.Order().PutUint16(w.scratch[:2], uint16(val))
+	return w.write("WriteInt16", w.scratch[:2])
 }
 
 // WriteInt32 write a int32 into buffer
 func (w *Writer) WriteInt32(val int32) error {
-	b := make([]byte, 4)
-	w.Order().PutUint32(b, uint32(val))
-	return w.WriteBytes(b)
+	w.Order().PutUint32(w.scratch[:4], uint32(val))
+	return w.write("WriteInt32", w.scratch[:4])
 }
 
 // WriteInt64 write a int64 into buffer
 func (w *Writer) WriteInt64(val int64) error {
-	b := make([]byte, 8)
-	w.Order().PutUint64(b, uint64(val))
-	return w.WriteBytes(b)
+	w.Order().PutUint64(w.scratch[:8], uint64(val))
+	return w.write("WriteInt64", w.scratch[:8])
 }
 
 // WriteFloat32 write a float32 into buffer
 func (w *Writer) WriteFloat32(val float32) error {
-	return w.WriteUint32(math.Float32bits(val))
+	return withOp(w.WriteUint32(math.Float32bits(val)), "WriteFloat32")
 }
 
 // WriteFloat64 write a float64 into buffer
 func (w *Writer) WriteFloat64(val float64) error {
-	return w.WriteUint64(math.Float64bits(val))
+	return withOp(w.WriteUint64(math.Float64bits(val)), "WriteFloat64")
+}
+
+// WriteUvarint write a uint64 as unsigned varint into buffer
+func (w *Writer) WriteUvarint(val uint64) error {
+	n := binary.PutUvarint(w.scratch[:], val)
+	return w.write("WriteUvarint", w.scratch[:n])
+}
+
+// WriteVarint write a int64 as zigzag encoded varint into buffer
+func (w *Writer) WriteVarint(val int64) error {
+	n := binary.PutVarint(w.scratch[:], val)
+	return w.write("WriteVarint", w.scratch[:n])
+}
+
+// WriteULEB128 write a uint64 as unsigned LEB128 into buffer
+func (w *Writer) WriteULEB128(val uint64) error {
+	return withOp(w.WriteUvarint(val), "WriteULEB128")
+}
+
+// WriteSLEB128 write a int64 as signed LEB128 into buffer
+func (w *Writer) WriteSLEB128(val int64) error {
+	n := putSLEB128(w.scratch[:maxLEB128Len64], val)
+	return w.write("WriteSLEB128", w.scratch[:n])
+}
+
+// WriteProtoVarint write a int64 as protobuf int32/int64 varint (two's complement, negatives take 10 bytes) into buffer
+func (w *Writer) WriteProtoVarint(val int64) error {
+	return withOp(w.WriteUvarint(uint64(val)), "WriteProtoVarint")
+}
+
+// WriteLengthPrefixed write length of b as prefix, followed by b
+func (w *Writer) WriteLengthPrefixed(prefix LengthPrefix, b []byte) error {
+	if err := w.writeLength(prefix, len(b)); err != nil {
+		return withOp(err, "WriteLengthPrefixed")
+	}
+	return w.write("WriteLengthPrefixed", b)
+}
+
+// WriteLengthPrefixedString write length of s as prefix, followed by s
+func (w *Writer) WriteLengthPrefixedString(prefix LengthPrefix, s string) error {
+	if err := w.writeLength(prefix, len(s)); err != nil {
+		return withOp(err, "WriteLengthPrefixedString")
+	}
+	return withOp(w.WriteString(s), "WriteLengthPrefixedString")
 }
@@ -3,18 +3,41 @@ package gobuf
 import (
 	"encoding/binary"
 	"io"
+	"net"
 )
 
+// defaultHoldGrow grow of memory holding data back while reservations are open
+const defaultHoldGrow = 512
+
 type IOWriter struct {
 	*Writer
 	writer io.Writer
 	order  binary.ByteOrder
+
+	// data is held in mem when buffered, or after the first open reservation until all reservations are filled.
+	// buffered data after writer mark is held until mark is dropped
+	// mem starts at writer index base and holds held bytes
+	mem          Memory
+	base         int
+	held         int
+	reservations map[int]int
+
+	// buffered data is flushed once held reaches threshold, 0 to only flush on Flush and Close
+	buffered  bool
+	threshold int
+	closed    bool
+	// segments reused when flushing Segmented memory
+	segments net.Buffers
 }
 
-func Write(w io.Writer, order binary.ByteOrder) *IOWriter {
+func Write(w io.Writer, order binary.ByteOrder, options ...WriterOptionFunc) *IOWriter {
 	writer := &IOWriter{
-		writer: w,
-		order:  order,
+		writer:       w,
+		order:        order,
+		reservations: map[int]int{},
+	}
+	for _, option := range options {
+		option(writer)
 	}
 	writer.Writer = NewWriter(writer)
 	return writer
@@ -25,5 +48,145 @@ func (w *IOWriter) Order() binary.ByteOrder {
 }
 
 func (w *IOWriter) WriteSome(src []byte) (n int, err error) {
-	return w.writer.Write(src)
+	if w.closed {
+		return 0, newError("Write", w.WriterIndex(), len(src), 0, ErrWriterClosed)
+	}
+
+	if w.held == 0 && len(w.reservations) == 0 && !w.Writer.marked {
+		// nothing to coalesce with, large writes skip the buffer
+		if !w.buffered || (w.threshold > 0 && len(src) >= w.threshold) {
+			return w.writer.Write(src)
+		}
+		w.base = w.WriterIndex()
+	}
+
+	if err = w.mem.Write(w.held, src); err != nil {
+		return 0, newError("Write", w.WriterIndex(), len(src), 0, err)
+	}
+	w.held += len(src)
+
+	if w.buffered && w.threshold > 0 && w.held >= w.threshold {
+		if err = w.flush(); err != nil {
+			return
+		}
+	}
+	return len(src), nil
+}
+
+// Buffered number of bytes held and not yet written to underlying writer
+func (w *IOWriter) Buffered() int {
+	return w.held
+}
+
+// Flush write buffered data to underlying writer, data from the first open reservation or writer mark on is held back
+func (w *IOWriter) Flush() error {
+	if w.closed {
+		return newError("Flush", w.WriterIndex(), 0, 0, ErrWriterClosed)
+	}
+	return w.flush()
+}
+
+// Close drop writer mark and flush buffered data, underlying writer is not closed. fails if any reservation is still open
+func (w *IOWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.UnmarkWriter()
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if len(w.reservations) > 0 {
+		return newError("Close", w.WriterIndex(), 0, 0, ErrUnfilledReservation)
+	}
+	w.closed = true
+	return nil
+}
+
+// ReserveAt implements Patchable, data is held back from underlying writer until every open reservation is filled
+func (w *IOWriter) ReserveAt(at, n int) (int, error) {
+	if w.held == 0 {
+		if w.mem == nil {
+			w.mem = NewSliceMemory(nil, FixedGrow(defaultHoldGrow))
+		}
+		w.base = at
+	}
+
+	w.reservations[at] = n
+	return at, nil
+}
+
+// Fill implements Patchable
+func (w *IOWriter) Fill(key int, src []byte) error {
+	if _, ok := w.reservations[key]; !ok {
+		return newError("Fill", key, len(src), 0, ErrNoReservation)
+	}
+
+	if err := w.mem.Write(key-w.base, src); err != nil {
+		return newError("Fill", key, len(src), 0, err)
+	}
+
+	delete(w.reservations, key)
+	if w.buffered && (w.threshold == 0 || w.held < w.threshold) {
+		return nil
+	}
+	return w.flush()
+}
+
+// flush write held data before the first open reservation and writer mark to underlying writer
+func (w *IOWriter) flush() error {
+	n := w.held
+	for at := range w.reservations {
+		if at-w.base < n {
+			n = at - w.base
+		}
+	}
+	if w.Writer.marked && w.Writer.mark-w.base < n {
+		n = w.Writer.mark - w.base
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// bytes written before a failure are dropped as well, so they are not sent again
+	written, err := w.output(n)
+	if written == 0 {
+		return err
+	}
+
+	// rest is behind an open reservation or was not written, move it to the front
+	if remain := w.held - written; remain > 0 {
+		if d, ok := w.mem.(Discardable); ok {
+			d.Discard(written)
+		} else if moveErr := moveMemory(w.mem, written, remain); moveErr != nil {
+			return moveErr
+		}
+	}
+	w.base += written
+	w.held -= written
+	return err
+}
+
+// output write first n held bytes to underlying writer, segmented memory is written without copying (writev on net.Conn).
+// returns number of bytes written, also when writing fails
+func (w *IOWriter) output(n int) (int, error) {
+	if s, ok := w.mem.(Segmented); ok {
+		w.segments = s.Segments(w.segments[:0], 0, n)
+		buffers := w.segments
+		written, err := buffers.WriteTo(w.writer)
+		if err == nil && written < int64(n) {
+			err = io.ErrShortWrite
+		}
+		return int(written), err
+	}
+
+	b := make([]byte, n)
+	if err := w.mem.Read(0, b); err != nil {
+		return 0, err
+	}
+
+	written, err := w.writer.Write(b)
+	if err == nil && written < n {
+		err = io.ErrShortWrite
+	}
+	return written, err
 }
@@ -3,11 +3,41 @@ package gobuf
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"io"
+	"net"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// countingWriter records every write it receives
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// limitedWriter accepts limit bytes, then fails
+type limitedWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) <= w.limit {
+		return w.Buffer.Write(p)
+	}
+	n, _ := w.Buffer.Write(p[:w.limit-w.Len()])
+	return n, errWriteFailed
+}
+
 var _ = Describe("Writer", func() {
 	It("should should writer", func() {
 
@@ -20,4 +50,111 @@ var _ = Describe("Writer", func() {
 		ExpectSizeError(6)(w.Write([]byte(" world")))
 		Expect(writer.String()).To(Equal("hello world"))
 	})
+
+	It("should coalesce writes until flushed", func() {
+		out := &countingWriter{}
+		w := Write(out, binary.BigEndian, WithWriteBuffer(NewSliceMemory(nil, FixedGrow(4)), 0))
+
+		for i := 0; i < 10; i++ {
+			Expect(w.WriteUint16(uint16(i))).To(BeNil())
+		}
+		Expect(out.writes).To(Equal(0))
+		Expect(w.Buffered()).To(Equal(20))
+		Expect(w.WriterIndex()).To(Equal(20))
+
+		Expect(w.Flush()).To(BeNil())
+		Expect(out.writes).To(Equal(1))
+		Expect(out.Len()).To(Equal(20))
+		Expect(out.Bytes()[18:]).To(Equal([]byte{0, 9}))
+		Expect(w.Buffered()).To(Equal(0))
+		Expect(w.Flush()).To(BeNil())
+		Expect(out.writes).To(Equal(1))
+	})
+
+	It("should flush at threshold", func() {
+		out := &countingWriter{}
+		w := Write(out, binary.BigEndian, WithWriteBuffer(NewListMemory(nil, FixedGrow(4)), 8))
+
+		Expect(w.WriteUint32(1)).To(BeNil())
+		Expect(out.writes).To(Equal(0))
+		Expect(w.WriteUint32(2)).To(BeNil())
+		Expect(out.writes).To(Equal(2))
+		Expect(out.Bytes()).To(Equal([]byte{0, 0, 0, 1, 0, 0, 0, 2}))
+
+		// large writes skip the buffer
+		Expect(w.WriteBytes(make([]byte, 16))).To(BeNil())
+		Expect(out.writes).To(Equal(3))
+		Expect(out.Len()).To(Equal(24))
+
+		Expect(w.WriteUint8(3)).To(BeNil())
+		Expect(w.Close()).To(BeNil())
+		Expect(out.Len()).To(Equal(25))
+		Expect(w.WriteUint8(4)).To(MatchError(ErrWriterClosed))
+		Expect(w.Flush()).To(MatchError(ErrWriterClosed))
+		Expect(w.Close()).To(BeNil())
+	})
+
+	It("should hold back buffered data behind open reservations", func() {
+		out := &countingWriter{}
+		w := Write(out, binary.BigEndian, WithWriteBuffer(nil, 4))
+
+		Expect(w.WriteString("ab")).To(BeNil())
+		r, err := w.Reserve(2)
+		Expect(err).To(BeNil())
+		Expect(w.WriteString("cdef")).To(BeNil())
+		Expect(out.String()).To(Equal("ab"))
+		Expect(w.Buffered()).To(Equal(6))
+		Expect(w.Close()).To(MatchError(ErrUnfilledReservation))
+
+		Expect(r.PutUint16(0x3132)).To(BeNil())
+		Expect(out.String()).To(Equal("ab12cdef"))
+		Expect(w.Close()).To(BeNil())
+	})
+
+	It("should write buffered chunks to net.Conn", func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).To(BeNil())
+		defer ln.Close()
+
+		received := make(chan []byte, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept()
+			Expect(err).To(BeNil())
+			b, err := io.ReadAll(conn)
+			Expect(err).To(BeNil())
+			received <- b
+		}()
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		Expect(err).To(BeNil())
+
+		w := Write(conn, binary.BigEndian, WithWriteBuffer(NewListMemory(nil, FixedGrow(3)), 0))
+		expected := []byte{}
+		for i := 0; i < 100; i++ {
+			Expect(w.WriteUint16(uint16(i))).To(BeNil())
+			expected = append(expected, byte(i>>8), byte(i))
+		}
+		Expect(w.Close()).To(BeNil())
+		Expect(conn.Close()).To(BeNil())
+		Expect(<-received).To(Equal(expected))
+	})
+
+	It("should not write bytes again after partial writes", func() {
+		for _, mem := range []Memory{NewListMemory(nil, FixedGrow(4)), NewSliceMemory(nil, FixedGrow(4))} {
+			out := &limitedWriter{limit: 3}
+			w := Write(out, binary.BigEndian, WithWriteBuffer(mem, 0))
+
+			Expect(w.WriteString("hello")).To(BeNil())
+			Expect(w.Flush()).To(MatchError(errWriteFailed))
+			Expect(out.String()).To(Equal("hel"))
+			Expect(w.Buffered()).To(Equal(2))
+
+			Expect(w.WriteString("!")).To(BeNil())
+			out.limit = 100
+			Expect(w.Flush()).To(BeNil())
+			Expect(out.String()).To(Equal("hello!"))
+			Expect(w.Buffered()).To(Equal(0))
+		}
+	})
 })